@@ -0,0 +1,172 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package main
+
+import (
+	"log/slog"
+	"myscript/internal/alignment"
+	"myscript/internal/cues"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Interval of the predicted reading positions pushed to the frontend
+const PREDICTION_INTERVAL = 250 * time.Millisecond
+
+type scriptAlignment struct {
+	aligner   *alignment.Aligner
+	predictor *alignment.Predictor
+	stop      chan struct{}
+}
+
+// StartScriptAlignment tokenizes the text of the page being read, as rendered by the
+// frontend (text content of the page), so the transcripts are aligned with it.
+// Numbers and abbreviations are spelled out for the language of the recording.
+// The reading position starts at the given UTF-16 offset.
+func (a *App) StartScriptAlignment(text, language string, offset int) {
+	aligner := alignment.NewAligner(text, language)
+	aligner.SetOffset(offset)
+
+	script := &scriptAlignment{
+		aligner:   aligner,
+		predictor: alignment.NewPredictor(len(aligner.Words()), aligner.Position()),
+		stop:      make(chan struct{}),
+	}
+
+	a.alignmentMu.Lock()
+	previous := a.alignment
+	a.alignment = script
+	a.alignmentMu.Unlock()
+
+	if previous != nil {
+		close(previous.stop)
+	}
+
+	go a.emitPredictions(script)
+
+	slog.Debug("Script alignment started", "words", len(aligner.Words()), "position", aligner.Position())
+}
+
+// SetScriptAlignmentOffset moves the reading position, e.g. when the user moves the marker
+func (a *App) SetScriptAlignmentOffset(offset int) {
+	script := a.getScriptAlignment()
+	if script == nil {
+		return
+	}
+
+	script.aligner.SetOffset(offset)
+	script.predictor.Observe(script.predictor.Mark(), script.aligner.Position())
+}
+
+func (a *App) StopScriptAlignment() {
+	a.alignmentMu.Lock()
+	script := a.alignment
+	a.alignment = nil
+	a.alignmentMu.Unlock()
+
+	if script != nil {
+		close(script.stop)
+	}
+}
+
+// AlignTranscript aligns a transcript with the script and moves the reading position.
+// It returns nil when the alignment is not started or no reliable match is found.
+func (a *App) AlignTranscript(transcript string) *alignment.Match {
+	return a.alignTranscript(transcript, a.markScriptAlignment())
+}
+
+// markScriptAlignment returns the speaking time at the end of an audio chunk,
+// to be given to alignTranscript with its transcript
+func (a *App) markScriptAlignment() time.Duration {
+	if script := a.getScriptAlignment(); script != nil {
+		return script.predictor.Mark()
+	}
+
+	return 0
+}
+
+// setReaderSpeaking updates the voice activity used to predict the reading position
+func (a *App) setReaderSpeaking(speaking bool) {
+	if script := a.getScriptAlignment(); script != nil {
+		script.predictor.SetSpeaking(speaking)
+	}
+}
+
+// alignTranscript aligns a transcript spoken before the mark, and pushes the match to the frontend
+func (a *App) alignTranscript(transcript string, mark time.Duration) *alignment.Match {
+	script := a.getScriptAlignment()
+	if script == nil {
+		return nil
+	}
+
+	position := script.aligner.Position()
+	match := script.aligner.Align(transcript)
+
+	a.addSessionCoverage(script.aligner, transcript, match, position)
+
+	if match == nil {
+		return nil
+	}
+
+	script.predictor.Observe(mark, match.EndWord)
+
+	slog.Debug("Transcript aligned", "start_word", match.StartWord, "end_word", match.EndWord, "confidence", match.Confidence)
+	runtime.EventsEmit(a.ctx, "on-script-aligned", match)
+
+	// Cue directives are fired once the reader has read the words before them
+	for _, cue := range script.aligner.CuesPassed(position, match.EndWord) {
+		slog.Debug("Cue reached", "type", cue.Type, "argument", cue.Argument, "word", cue.Word)
+		runtime.EventsEmit(a.ctx, "on-cue-reached", cue)
+	}
+
+	return match
+}
+
+// emitPredictions pushes the predicted reading position at a steady rate, while recording
+func (a *App) emitPredictions(script *scriptAlignment) {
+	ticker := time.NewTicker(PREDICTION_INTERVAL)
+	defer ticker.Stop()
+
+	words := script.aligner.Words()
+	lastWord := -1
+
+	for {
+		select {
+		case <-script.stop:
+			return
+		case <-ticker.C:
+		}
+
+		if !a.audioSequencer.Recording() || a.audioSequencer.Paused() {
+			continue
+		}
+
+		word := script.predictor.Predict()
+		if word == lastWord || word >= len(words) {
+			continue
+		}
+		lastWord = word
+
+		runtime.EventsEmit(a.ctx, "on-script-predicted", alignment.Prediction{
+			Word:           word,
+			Start:          words[word].Start,
+			End:            words[word].End,
+			WordsPerMinute: script.predictor.WordsPerMinute(),
+		})
+	}
+}
+
+// GetScriptCues returns the cue directives of the text given to StartScriptAlignment, with their
+// UTF-16 offsets in it and the index of the word following them as counted by the script alignment
+func (a *App) GetScriptCues(text, language string) []cues.Cue {
+	return alignment.NewAligner(text, language).Cues()
+}
+
+func (a *App) getScriptAlignment() *scriptAlignment {
+	a.alignmentMu.Lock()
+	defer a.alignmentMu.Unlock()
+
+	return a.alignment
+}
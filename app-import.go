@@ -0,0 +1,56 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package main
+
+import (
+	"myscript/internal/importer"
+	"myscript/internal/repository"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Document Import ---
+
+// ImportDocuments asks the user for Word documents, plain text or Fountain screenplays and
+// imports each one as a page at the end of a folder, or of the root without parentID.
+// It returns the created pages with the elements that could not be imported,
+// or nothing if the user cancelled.
+func (a *App) ImportDocuments(parentID *string) ([]importer.Result, error) {
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import documents",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Documents (*.docx, *.txt, *.fountain)", Pattern: "*.docx;*.txt;*.text;*.fountain"},
+			{DisplayName: "Word documents (*.docx)", Pattern: "*.docx"},
+			{DisplayName: "Plain text (*.txt)", Pattern: "*.txt;*.text"},
+			{DisplayName: "Fountain screenplays (*.fountain)", Pattern: "*.fountain"},
+		},
+	})
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+
+	results := make([]importer.Result, 0, len(paths))
+	pages := make([]repository.Page, 0, len(paths))
+
+	for _, path := range paths {
+		result, err := importer.ImportFile(path)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, *result)
+		pages = append(pages, result.Page)
+	}
+
+	pages, err = repository.NewPageRepository(a.mainDB).
+		ImportPages(parentID, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i].Page = pages[i]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package main
+
+import (
+	"fmt"
+	"myscript/internal/markdown"
+	"myscript/internal/repository"
+	"os"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Markdown ---
+
+var markdownFilter = runtime.FileFilter{
+	DisplayName: "Markdown (*.md, *.markdown)",
+	Pattern:     "*.md;*.markdown",
+}
+
+// ImportMarkdownFiles asks the user for Markdown files and imports each one as a page
+// at the end of a folder, or of the root without parentID.
+// It returns the created pages, or nothing if the user cancelled.
+func (a *App) ImportMarkdownFiles(parentID *string) ([]repository.Page, error) {
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import Markdown files",
+		Filters: []runtime.FileFilter{markdownFilter},
+	})
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+
+	pages := make([]repository.Page, 0, len(paths))
+	for _, path := range paths {
+		page, err := markdown.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+
+	return repository.NewPageRepository(a.mainDB).
+		ImportPages(parentID, pages)
+}
+
+// ImportMarkdownDirectory asks the user for a directory and imports it as a folder,
+// its Markdown files becoming pages and its subdirectories subfolders.
+// It returns the created folder, or nil if the user cancelled.
+func (a *App) ImportMarkdownDirectory(parentID *string) (*repository.Page, error) {
+	dir, err := runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "Import a Markdown directory",
+	})
+	if err != nil || dir == "" {
+		return nil, err
+	}
+
+	folder, found, err := markdown.ReadDirectory(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no Markdown file found in %s", dir)
+	}
+
+	pages, err := repository.NewPageRepository(a.mainDB).
+		ImportPages(parentID, []repository.Page{folder})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pages[0], nil
+}
+
+// ExportMarkdown asks the user for a destination and writes a page, or a folder with
+// all its descendants, as Markdown files in a zip archive.
+// It returns the chosen path, or an empty string if the user cancelled.
+func (a *App) ExportMarkdown(ID string) (string, error) {
+	page, err := repository.NewPageRepository(a.mainDB).
+		GetPageTree(ID)
+	if err != nil {
+		return "", err
+	}
+
+	title := strings.TrimSpace(strings.NewReplacer("/", "-", "\\", "-").Replace(page.Title))
+	if title == "" {
+		title = "page"
+	}
+
+	destination, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export as Markdown",
+		DefaultFilename: title + ".zip",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Zip archive (*.zip)", Pattern: "*.zip"},
+		},
+	})
+	if err != nil || destination == "" {
+		return "", err
+	}
+
+	target, err := os.Create(destination)
+	if err != nil {
+		return "", err
+	}
+	defer target.Close()
+
+	if err := markdown.WriteZip(target, page); err != nil {
+		return "", err
+	}
+
+	return destination, nil
+}
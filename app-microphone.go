@@ -0,0 +1,260 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"myscript/internal/repository"
+	"myscript/internal/utils"
+	"myscript/internal/utils/microphone"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// --- Microphone settings ---
+
+func (a *App) getMicInputDevice(micInputDeviceID string) (*microphone.MicInputDevice, error) {
+	micDeviceID, err := utils.B64toBytes(micInputDeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid microphone input device")
+	}
+
+	return a.audioSequencer.GetMicInputDevice(micDeviceID)
+}
+
+func (a *App) GetMicrophoneSetting(deviceName string) *repository.MicrophoneSetting {
+	return repository.NewMicrophoneSettingRepository(a.unSyncedDB).
+		GetMicrophoneSetting(deviceName)
+}
+
+func (a *App) SaveMicrophoneSetting(setting *repository.MicrophoneSetting) *repository.MicrophoneSetting {
+	return repository.NewMicrophoneSettingRepository(a.unSyncedDB).
+		SaveMicrophoneSetting(setting)
+}
+
+func (a *App) ResetMicrophoneSetting(deviceName string) {
+	repository.NewMicrophoneSettingRepository(a.unSyncedDB).
+		DeleteMicrophoneSetting(deviceName)
+}
+
+// applyMicrophoneSetting configures the audio sequencer with the saved settings of the device
+func (a *App) applyMicrophoneSetting(deviceName string) {
+	setting := a.GetMicrophoneSetting(deviceName)
+
+	noiseThreshold := float64(microphone.DEFAULT_NOISE_THRESHOLD)
+	if setting.NoiseThreshold != nil {
+		noiseThreshold = *setting.NoiseThreshold
+	}
+
+	triggerDecibels := float64(microphone.DEFAULT_TRIGGER_DECIBELS)
+	if setting.TriggerDecibels != nil {
+		triggerDecibels = *setting.TriggerDecibels
+	}
+
+	a.audioSequencer.SetThresholds(noiseThreshold, triggerDecibels)
+
+	voiceDetector := microphone.DEFAULT_VAD
+	if setting.VoiceDetector != nil {
+		voiceDetector = *setting.VoiceDetector
+	}
+
+	if err := a.audioSequencer.SetVoiceDetector(voiceDetector); err != nil {
+		slog.Error("Invalid voice detector setting", "device", deviceName, "error", err)
+		a.audioSequencer.SetVoiceDetector(microphone.DEFAULT_VAD)
+	}
+
+	if err := a.applyChannelSelection(setting); err != nil {
+		slog.Error("Invalid channel selection setting", "device", deviceName, "error", err)
+		a.audioSequencer.SetChannelSelection(0, nil)
+	}
+
+	processing := microphone.ProcessingConfig{
+		HighPassFilter: setting.HighPassFilter != nil && *setting.HighPassFilter,
+		NoiseReduction: setting.NoiseReduction != nil && *setting.NoiseReduction,
+		AutoGain:       setting.AutoGain != nil && *setting.AutoGain,
+	}
+	if setting.AutoGainTarget != nil {
+		processing.AutoGainTarget = *setting.AutoGainTarget
+	}
+
+	a.audioSequencer.SetProcessing(processing)
+}
+
+func (a *App) applyChannelSelection(setting *repository.MicrophoneSetting) error {
+	if setting.CaptureChannels == nil || *setting.CaptureChannels == 0 {
+		return a.audioSequencer.SetChannelSelection(0, nil)
+	}
+
+	var inputChannels []uint32
+	if setting.InputChannels != nil {
+		for _, value := range strings.Split(*setting.InputChannels, ",") {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+
+			channel, err := strconv.ParseUint(value, 10, 32)
+			if err != nil || channel == 0 {
+				return fmt.Errorf("invalid channel %q", value)
+			}
+
+			inputChannels = append(inputChannels, uint32(channel-1))
+		}
+	}
+
+	return a.audioSequencer.SetChannelSelection(*setting.CaptureChannels, inputChannels)
+}
+
+func (a *App) GetVoiceDetectors() []string {
+	return microphone.GetVoiceDetectors()
+}
+
+// --- Device fallback ---
+
+// handleMicDeviceLost is called by the audio sequencer when the recording device disappears.
+// The recording continues on the fallback device when possible, otherwise it is stopped.
+func (a *App) handleMicDeviceLost() {
+	a.sessionMu.Lock()
+	session := a.session
+	a.sessionMu.Unlock()
+
+	if session == nil {
+		return
+	}
+
+	lostDeviceName := session.deviceName
+	runtime.EventsEmit(a.ctx, "on-mic-device-lost", lostDeviceName)
+
+	fallback, err := a.findFallbackMicInputDevice(lostDeviceName)
+	if err == nil {
+		a.applyMicrophoneSetting(fallback.Name)
+		err = a.audioSequencer.SwitchDevice(fallback.ID[:])
+	}
+
+	if err != nil {
+		slog.Error("Cannot continue the recording on another microphone", "lost", lostDeviceName, "error", err)
+		a.audioSequencer.Stop(true)
+		return
+	}
+
+	a.sessionMu.Lock()
+	session.deviceName = fallback.Name
+	// The stored session names the device the recording ends on
+	session.record.DeviceName = fallback.Name
+	repository.NewRecordingSessionRepository(a.unSyncedDB).
+		SaveRecordingSession(session.record)
+	a.sessionMu.Unlock()
+
+	slog.Debug("Recording switched to the fallback microphone", "lost", lostDeviceName, "device", fallback.Name)
+	runtime.EventsEmit(a.ctx, "on-mic-device-switched", fallback.Name)
+}
+
+// findFallbackMicInputDevice returns the configured backup device of the lost device,
+// or the system default device
+func (a *App) findFallbackMicInputDevice(lostDeviceName string) (*microphone.MicInputDevice, error) {
+	setting := a.GetMicrophoneSetting(lostDeviceName)
+	if setting.AutoFallback != nil && !*setting.AutoFallback {
+		return nil, fmt.Errorf("automatic fallback is disabled for %s", lostDeviceName)
+	}
+
+	devices, err := a.audioSequencer.GetMicInputDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultDevice *microphone.MicInputDevice
+
+	for i, device := range devices {
+		if device.Name == lostDeviceName {
+			continue
+		}
+
+		if setting.FallbackDeviceName != nil && device.Name == *setting.FallbackDeviceName {
+			return &devices[i], nil
+		}
+
+		if device.IsDefault == 1 && defaultDevice == nil {
+			defaultDevice = &devices[i]
+		}
+	}
+
+	if defaultDevice == nil {
+		return nil, fmt.Errorf("no fallback microphone available")
+	}
+
+	return defaultDevice, nil
+}
+
+// --- Calibration ---
+
+// CalibrateMicrophone records the room tone for the given seconds, saves the measured
+// noise floor for the device and returns the suggested thresholds.
+// The user should stay silent during the calibration.
+func (a *App) CalibrateMicrophone(micInputDeviceID string, seconds int) (*microphone.CalibrationResult, error) {
+	device, err := a.getMicInputDevice(micInputDeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The levels are measured after the pre-processing of the device
+	a.applyMicrophoneSetting(device.Name)
+
+	levels, err := a.audioSequencer.MeasureLevels(device.ID[:], time.Duration(seconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	noiseFloor, noiseVariance := microphone.NoiseStatistics(levels)
+
+	result, err := a.audioSequencer.SuggestThresholds(noiseFloor, noiseVariance, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	setting := a.GetMicrophoneSetting(device.Name)
+	setting.NoiseFloor = &result.NoiseFloor
+	setting.NoiseVariance = &result.NoiseVariance
+	setting.SpeechLevel = nil
+	setting.CalibratedAt = &now
+	a.SaveMicrophoneSetting(setting)
+
+	return result, nil
+}
+
+// CalibrateMicrophoneSpeech is the optional second calibration step: the user reads
+// a test sentence for the given seconds, so the speech level can be measured.
+// It requires CalibrateMicrophone to have been run first for the device.
+func (a *App) CalibrateMicrophoneSpeech(micInputDeviceID string, seconds int) (*microphone.CalibrationResult, error) {
+	device, err := a.getMicInputDevice(micInputDeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	setting := a.GetMicrophoneSetting(device.Name)
+	if setting.NoiseFloor == nil || setting.NoiseVariance == nil {
+		return nil, fmt.Errorf("The room tone must be calibrated first.")
+	}
+
+	a.applyMicrophoneSetting(device.Name)
+
+	levels, err := a.audioSequencer.MeasureLevels(device.ID[:], time.Duration(seconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := a.audioSequencer.SuggestThresholds(*setting.NoiseFloor, *setting.NoiseVariance, levels)
+	if err != nil {
+		return nil, err
+	}
+
+	setting.SpeechLevel = result.SpeechLevel
+	a.SaveMicrophoneSetting(setting)
+
+	return result, nil
+}
@@ -4,8 +4,10 @@
 package main
 
 import (
+	"log/slog"
 	"myscript/internal/notion"
 	"myscript/internal/repository"
+	"time"
 
 	"github.com/jomei/notionapi"
 )
@@ -53,12 +55,112 @@ func (a *App) SaveLocalPage(page *repository.Page) *repository.Page {
 		SavePage(page)
 }
 
-func (a *App) DeleteLocalPage(ID string) {
-	repository.NewPageRepository(a.mainDB).
+func (a *App) DeleteLocalPage(ID string) error {
+	return repository.NewPageRepository(a.mainDB).
 		DeletePage(ID)
 }
 
-func (a *App) UpdateLocalPageOrder(ID string, ParentID *string, order int) {
-	repository.NewPageRepository(a.mainDB).
+// SearchPages searches the title and the text of the local pages, best matches first
+func (a *App) SearchPages(query string) []repository.PageSearchResult {
+	return repository.NewPageRepository(a.mainDB).
+		SearchPages(query)
+}
+
+func (a *App) UpdateLocalPageOrder(ID string, ParentID *string, order int) error {
+	return repository.NewPageRepository(a.mainDB).
 		UpdatePageOrder(ID, ParentID, order)
 }
+
+// MoveLocalPage moves a page and its descendants to a position of a folder, or of the root without parentID
+func (a *App) MoveLocalPage(ID string, parentID *string, position int) error {
+	return repository.NewPageRepository(a.mainDB).
+		MovePage(ID, parentID, position)
+}
+
+// DuplicateLocalPage copies a page, or a folder with all its descendants
+func (a *App) DuplicateLocalPage(ID string) (*repository.Page, error) {
+	return repository.NewPageRepository(a.mainDB).
+		DuplicatePage(ID)
+}
+
+// --- Trash ---
+
+func (a *App) GetTrashedPages() []repository.Page {
+	return repository.NewPageRepository(a.mainDB).
+		GetTrashedPages()
+}
+
+func (a *App) RestoreLocalPage(ID string) (*repository.Page, error) {
+	return repository.NewPageRepository(a.mainDB).
+		RestorePage(ID)
+}
+
+func (a *App) PurgeLocalPage(ID string) error {
+	return repository.NewPageRepository(a.mainDB).
+		PurgePage(ID)
+}
+
+func (a *App) EmptyTrash() error {
+	return repository.NewPageRepository(a.mainDB).
+		EmptyTrash()
+}
+
+// purgeTrash permanently deletes the pages kept in the trash longer than the configured retention
+func (a *App) purgeTrash() {
+	retentionDays := repository.DEFAULT_TRASH_RETENTION_DAYS
+	if config := a.GetConfig(); config != nil && config.TrashRetentionDays != nil {
+		retentionDays = *config.TrashRetentionDays
+	}
+
+	count, err := repository.NewPageRepository(a.mainDB).PurgeExpiredPages(retentionDays)
+	if err != nil {
+		slog.Error("Failed to purge the trash", "error", err)
+	} else if count > 0 {
+		slog.Debug("Expired pages purged from the trash", "count", count)
+	}
+}
+
+// scheduleTrashPurge purges the trash on startup, then once a day
+func (a *App) scheduleTrashPurge() {
+	a.purgeTrash()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.purgeTrash()
+		}
+	}
+}
+
+// --- Page Revisions ---
+
+func (a *App) GetPageRevisions(pageID string) []repository.PageRevision {
+	return repository.NewPageRevisionRepository(a.mainDB).
+		GetPageRevisions(pageID)
+}
+
+func (a *App) GetPageRevision(ID string) *repository.PageRevision {
+	return repository.NewPageRevisionRepository(a.mainDB).
+		GetPageRevision(ID)
+}
+
+// DiffPageRevisions compares two revisions, or a revision with the current page when toID is empty
+func (a *App) DiffPageRevisions(fromID, toID string) *repository.PageRevisionDiff {
+	return repository.NewPageRevisionRepository(a.mainDB).
+		DiffPageRevisions(fromID, toID)
+}
+
+func (a *App) RestorePageRevision(ID string) *repository.Page {
+	return repository.NewPageRevisionRepository(a.mainDB).
+		RestorePageRevision(ID)
+}
+
+func (a *App) DeletePageRevision(ID string) {
+	repository.NewPageRevisionRepository(a.mainDB).
+		DeletePageRevision(ID)
+}
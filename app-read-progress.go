@@ -0,0 +1,52 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package main
+
+import (
+	"fmt"
+	"myscript/internal/repository"
+)
+
+// --- Read Progress ---
+
+// GetReadProgress returns the latest reading position of a page on any device.
+// Pages read before the progress had its own table are resumed from the cache.
+func (a *App) GetReadProgress(pageID string) *repository.ReadProgress {
+	if progress := repository.NewReadProgressRepository(a.mainDB).GetReadProgress(pageID); progress != nil {
+		return progress
+	}
+
+	return a.getLegacyReadProgress(pageID)
+}
+
+// SaveReadProgress records the reading position of a page on this device
+func (a *App) SaveReadProgress(pageID string, progress int, total int) *repository.ReadProgress {
+	deviceID := repository.NewSyncStateRepository(a.unSyncedDB).GetDeviceID()
+
+	return repository.NewReadProgressRepository(a.mainDB).
+		SaveReadProgress(pageID, deviceID, progress, total)
+}
+
+// getLegacyReadProgress reads the progress saved by SaveCache as {progress, total}
+func (a *App) getLegacyReadProgress(pageID string) *repository.ReadProgress {
+	cache := repository.NewCacheRepository(a.mainDB).
+		GetCache(fmt.Sprintf("page-%s-read-progress", pageID))
+	if cache == nil {
+		return nil
+	}
+
+	value, ok := cache.Value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	progress, _ := value["progress"].(float64)
+	total, _ := value["total"].(float64)
+
+	return &repository.ReadProgress{
+		PageID:   pageID,
+		Progress: int(progress),
+		Total:    int(total),
+	}
+}
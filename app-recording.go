@@ -4,24 +4,93 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"myscript/internal/alignment"
+	"myscript/internal/captions"
+	"myscript/internal/repository"
 	"myscript/internal/utils"
 	"myscript/internal/utils/microphone"
+	"path/filepath"
+	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
-func (a *App) StartRecording(language string, micInputDeviceID string) error {
+// Maximum number of words compared when joining the transcripts of overlapping chunks
+const MAX_OVERLAP_WORDS = 8
+
+type recordingSession struct {
+	pageID     string
+	language   string
+	deviceName string
+	startedAt  time.Time
+
+	// Saved for every session
+	record  *repository.RecordingSession
+	reading *repository.ReadingSession
+
+	// Speaking time of the script alignment when the session started
+	speechStart time.Duration
+
+	// Session audio, when enabled in the config
+	audioWriter *microphone.WavFileWriter
+
+	// Transcripts compared with the script, when the script alignment is started
+	coverage *alignment.Coverage
+
+	// Timed transcripts, for the captions export
+	transcript []captions.Segment
+}
+
+func (a *App) StartRecording(pageID string, language string, micInputDeviceID string) error {
 	micDeviceID, err := utils.B64toBytes(micInputDeviceID)
 	if err != nil {
 		return fmt.Errorf("Invalid microphone input device")
 	}
 
+	micDevice, err := a.audioSequencer.GetMicInputDevice(micDeviceID)
+	if err != nil {
+		return err
+	}
+
+	return a.startRecording(pageID, language, micDevice.Name, func() error {
+		return a.audioSequencer.Start(micDeviceID)
+	})
+}
+
+// DevStartFileReplay runs a recording session from a WAV file instead of the microphone.
+// A speed of 1 replays the file in real time, 0 as fast as possible. Only available in dev mode.
+func (a *App) DevStartFileReplay(pageID string, language string, wavFilePath string, speed float64) error {
+	if !utils.IsDevMode() {
+		return fmt.Errorf("file replay is only available in dev mode")
+	}
+
+	source, err := microphone.NewFileSource(wavFilePath, speed, a.audioSequencer.GetNoiseConfig())
+	if err != nil {
+		return err
+	}
+
+	return a.startRecording(pageID, language, filepath.Base(wavFilePath), func() error {
+		return a.audioSequencer.StartSource(source)
+	})
+}
+
+// startRecording prepares the transcription pipeline and the session,
+// then starts the audio sequencer with the given function
+func (a *App) startRecording(pageID, language, deviceName string, start func() error) error {
 	if config := a.GetConfig(); config.TranscriberSource == "" {
 		return fmt.Errorf("No transcription source has been configured.")
 	}
 
+	// The callbacks and the session of the ongoing recording must not be replaced
+	if a.audioSequencer.Recording() {
+		return fmt.Errorf("A recording is already in progress.")
+	}
+
+	a.applyMicrophoneSetting(deviceName)
+
 	// If transcriber source is set to local, load the model
 	if err := a.initLocalWhisperTranscriber(language); err != nil {
 		return err
@@ -29,26 +98,59 @@ func (a *App) StartRecording(language string, micInputDeviceID string) error {
 
 	pq := utils.NewProcessQueue("transcriber-queue")
 
-	a.audioSequencer.SetSequentializeCallback(func(buffer []byte) {
+	// Last emitted transcript, only accessed from the process queue
+	var lastTranscript string
+
+	a.audioSequencer.SetSequentializeCallback(func(chunk microphone.AudioChunk) {
 		bookId := pq.Book()
+		// Speaking time at the end of the chunk, to estimate the reading pace
+		mark := a.markScriptAlignment()
 
-		slog.Debug("AudioSequencer: new audio chunk", "chunk", len(buffer), "transcribing", true)
+		slog.Debug("AudioSequencer: new audio chunk", "chunk", len(chunk.Data), "overlap", chunk.Overlap, "transcribing", true)
 
-		waveBuffer, _ := a.audioSequencer.RawBytesToWAV(buffer)
+		waveBuffer, _ := a.audioSequencer.RawBytesToWAV(chunk.Data)
 		transcribed, err := a.Transcribe(waveBuffer, language)
 
 		if err != nil {
 			slog.Error("Transcription error", "error", err)
 			runtime.EventsEmit(a.ctx, "on-transcribe-error", err.Error())
+
+			// Release the booked place, so the next chunks are not blocked
+			pq.Add(bookId, func() {
+				lastTranscript = ""
+			})
 			return
 		}
 
 		pq.Add(bookId, func() {
-			runtime.EventsEmit(a.ctx, "on-transcribed-text", transcribed)
+			text := transcribed
+
+			// The chunk starts with the end of the previous one, drop the repeated words
+			if chunk.Overlap > 0 {
+				text = utils.TrimOverlap(lastTranscript, text, MAX_OVERLAP_WORDS)
+			}
+
+			lastTranscript = transcribed
+			runtime.EventsEmit(a.ctx, "on-transcribed-text", text)
+
+			match := a.alignTranscript(text, mark)
+			a.addSessionTranscript(chunk, text, match)
 		})
 	})
 
+	a.beginSession(pageID, language, deviceName)
+
+	a.audioSequencer.SetLevelCallback(func(level microphone.AudioLevel) {
+		runtime.EventsEmit(a.ctx, "on-audio-level", level)
+		a.setReaderSpeaking(level.InSpeech)
+	})
+
+	a.audioSequencer.SetDeviceLostCallback(a.handleMicDeviceLost)
+
 	a.audioSequencer.SetStopCallback(func(autoStopped bool) {
+		// The transcripts of the last chunks are still part of the session
+		pq.Wait()
+		a.endSession()
 		runtime.EventsEmit(a.ctx, "on-recording-stopped", autoStopped)
 		// Unload local whisper model, if it is loaded
 		go a.lwt.Close()
@@ -56,7 +158,13 @@ func (a *App) StartRecording(language string, micInputDeviceID string) error {
 
 	slog.Debug("Starting recording with language", "language", language)
 
-	return a.audioSequencer.Start(micDeviceID)
+	if err := start(); err != nil {
+		// Closes the session audio before its file is deleted
+		a.discardSession()
+		return err
+	}
+
+	return nil
 }
 
 func (a *App) StopRecording() {
@@ -65,9 +173,253 @@ func (a *App) StopRecording() {
 	a.audioSequencer.Stop(false)
 	// Should be called after Stop()
 	a.audioSequencer.SetSequentializeCallback(nil)
+	a.audioSequencer.SetLevelCallback(nil)
+	a.audioSequencer.SetFramesCallback(nil)
+	a.audioSequencer.SetDeviceLostCallback(nil)
 	a.audioSequencer.SetStopCallback(nil)
 }
 
+func (a *App) beginSession(pageID, language, deviceName string) {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	session := &recordingSession{
+		pageID:     pageID,
+		language:   language,
+		deviceName: deviceName,
+		startedAt:  time.Now(),
+	}
+
+	session.record = repository.NewRecordingSessionRepository(a.unSyncedDB).
+		SaveRecordingSession(&repository.RecordingSession{
+			PageID:     pageID,
+			DeviceName: deviceName,
+			Language:   language,
+			StartedAt:  session.startedAt,
+		})
+
+	session.reading = repository.NewReadingSessionRepository(a.mainDB).
+		SaveReadingSession(&repository.ReadingSession{
+			PageID:    pageID,
+			StartedAt: session.startedAt,
+			Language:  language,
+			Provider:  a.GetConfig().TranscriberSource,
+		})
+	session.speechStart = a.markScriptAlignment()
+
+	a.audioSequencer.SetFramesCallback(nil)
+
+	if config := a.GetConfig(); config.RecordSessionAudio != nil && *config.RecordSessionAudio {
+		if err := a.startSessionAudio(session); err != nil {
+			slog.Error("Failed to start the session audio recording", "error", err)
+		}
+	}
+
+	a.session = session
+}
+
+// endSession is called once the audio sequencer is stopped
+func (a *App) endSession() {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	session := a.session
+	if session == nil {
+		return
+	}
+
+	a.session = nil
+	a.stopSessionAudio(session)
+
+	now := time.Now()
+	session.record.EndedAt = &now
+	if session.audioWriter == nil {
+		session.record.Duration = now.Sub(session.startedAt).Milliseconds()
+	}
+
+	var report *alignment.CoverageReport
+	if session.coverage != nil {
+		report = session.coverage.Report()
+
+		if data, err := json.Marshal(report); err == nil {
+			session.record.Coverage = data
+		}
+	}
+
+	if len(session.transcript) > 0 {
+		if data, err := json.Marshal(session.transcript); err == nil {
+			session.record.Transcript = data
+		}
+	}
+
+	repository.NewRecordingSessionRepository(a.unSyncedDB).
+		SaveRecordingSession(session.record)
+
+	a.saveReadingSession(session, report)
+
+	if report != nil {
+		runtime.EventsEmit(a.ctx, "on-coverage-report", session.record.ID, report)
+	}
+}
+
+// discardSession removes the session of a recording that failed to start
+func (a *App) discardSession() {
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	session := a.session
+	if session == nil {
+		return
+	}
+
+	a.session = nil
+	a.stopSessionAudio(session)
+
+	if err := a.DeleteRecordingSession(session.record.ID); err != nil {
+		slog.Error("Failed to delete the session", "error", err)
+	}
+
+	repository.NewReadingSessionRepository(a.mainDB).
+		DeleteReadingSession(session.reading.ID)
+}
+
+// saveReadingSession completes the practice history of an ended session
+func (a *App) saveReadingSession(session *recordingSession, report *alignment.CoverageReport) {
+	reading := session.reading
+	reading.EndedAt = session.record.EndedAt
+	reading.Duration = session.record.Duration
+
+	if report != nil {
+		reading.WordsCovered = report.WordsCovered
+		reading.ErrorCount = report.Skipped + report.Paraphrased + report.Inserted
+
+		// The speaking time is reset when the script alignment is restarted during the session
+		speaking := a.markScriptAlignment() - session.speechStart
+		if speaking <= 0 {
+			speaking = time.Duration(reading.Duration) * time.Millisecond
+		}
+		if speaking > 0 {
+			reading.WordsPerMinute = float64(reading.WordsCovered) / speaking.Minutes()
+		}
+	}
+
+	repository.NewReadingSessionRepository(a.mainDB).
+		SaveReadingSession(reading)
+}
+
+// GetReadingSessions returns the practice history of a page, latest first
+func (a *App) GetReadingSessions(pageID string) []repository.ReadingSession {
+	return repository.NewReadingSessionRepository(a.mainDB).
+		GetReadingSessions(pageID)
+}
+
+// GetPageReadingStats returns how often and how well a page was rehearsed
+func (a *App) GetPageReadingStats(pageID string) repository.ReadingStats {
+	return repository.NewReadingSessionRepository(a.mainDB).
+		GetPageReadingStats(pageID)
+}
+
+// GetReadingStats returns the practice stats of every rehearsed page
+func (a *App) GetReadingStats() []repository.ReadingStats {
+	return repository.NewReadingSessionRepository(a.mainDB).
+		GetReadingStats()
+}
+
+// addSessionCoverage records an aligned transcript for the coverage report of the session
+func (a *App) addSessionCoverage(aligner *alignment.Aligner, transcript string, match *alignment.Match, position int) {
+	a.sessionMu.Lock()
+	session := a.session
+	if session == nil {
+		a.sessionMu.Unlock()
+		return
+	}
+
+	// The script may have been reloaded during the session
+	if session.coverage == nil || session.coverage.Aligner() != aligner {
+		session.coverage = alignment.NewCoverage(aligner)
+	}
+	coverage := session.coverage
+	a.sessionMu.Unlock()
+
+	coverage.Add(transcript, match, position)
+}
+
+// addSessionTranscript records the transcript of a chunk with its timing. The audio shared
+// with the previous chunk is skipped, as its words were dropped from the transcript.
+func (a *App) addSessionTranscript(chunk microphone.AudioChunk, text string, match *alignment.Match) {
+	if text == "" {
+		return
+	}
+
+	segment := captions.Segment{
+		Start: (chunk.Offset + chunk.Overlap).Milliseconds(),
+		End:   (chunk.Offset + chunk.Duration).Milliseconds(),
+		Text:  text,
+	}
+	if match != nil {
+		segment.Script = match.Script
+		segment.Confidence = match.Confidence
+	}
+
+	a.sessionMu.Lock()
+	defer a.sessionMu.Unlock()
+
+	if a.session != nil {
+		a.session.transcript = append(a.session.transcript, segment)
+	}
+}
+
+// GetCoverageReport returns the script coverage report saved with a session,
+// nil if the script alignment was not used during the session
+func (a *App) GetCoverageReport(recordingSessionID uint) (*alignment.CoverageReport, error) {
+	record := repository.NewRecordingSessionRepository(a.unSyncedDB).
+		GetRecordingSession(recordingSessionID)
+	if record == nil {
+		return nil, fmt.Errorf("recording session not found")
+	}
+
+	if len(record.Coverage) == 0 {
+		return nil, nil
+	}
+
+	var report alignment.CoverageReport
+	if err := json.Unmarshal(record.Coverage, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// PauseRecording keeps the microphone and the transcription model ready,
+// so the session can be resumed without losing its state
+func (a *App) PauseRecording() error {
+	if err := a.audioSequencer.Pause(); err != nil {
+		return err
+	}
+
+	a.setReaderSpeaking(false)
+
+	slog.Debug("Recording paused")
+	runtime.EventsEmit(a.ctx, "on-recording-paused")
+
+	return nil
+}
+
+func (a *App) ResumeRecording() error {
+	if err := a.audioSequencer.Resume(); err != nil {
+		return err
+	}
+
+	slog.Debug("Recording resumed")
+	runtime.EventsEmit(a.ctx, "on-recording-resumed")
+
+	return nil
+}
+
+func (a *App) IsRecordingPaused() bool {
+	return a.audioSequencer.Paused()
+}
+
 func (a *App) IsRecording() bool {
 	return a.audioSequencer.Recording()
 }
@@ -75,3 +427,8 @@ func (a *App) IsRecording() bool {
 func (a *App) GetMicInputDevices() ([]microphone.MicInputDevice, error) {
 	return a.audioSequencer.GetMicInputDevices()
 }
+
+// This is just a helper function, microphone.AudioLevel struct is generated by Wails
+func (a *App) GetAudioLevelPlaceholder() microphone.AudioLevel {
+	return microphone.AudioLevel{}
+}
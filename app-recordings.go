@@ -0,0 +1,233 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"myscript/internal/captions"
+	"myscript/internal/filesystem"
+	"myscript/internal/repository"
+	"myscript/internal/utils/microphone"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	RECORDINGS_DIR = "recordings"
+
+	// Recordings are served to the frontend under this path
+	RECORDINGS_URL_PREFIX = "/recordings/"
+)
+
+// --- Session audio ---
+
+func (a *App) startSessionAudio(session *recordingSession) error {
+	dir, err := filesystem.GetHomeSubDir(RECORDINGS_DIR)
+	if err != nil {
+		return err
+	}
+
+	noiseConfig := a.audioSequencer.GetNoiseConfig()
+	fileName := uuid.New().String() + ".wav"
+
+	writer, err := microphone.NewWavFileWriter(filepath.Join(dir, fileName), noiseConfig.SampleRate, noiseConfig.Channels)
+	if err != nil {
+		return err
+	}
+
+	session.audioWriter = writer
+	session.record.FileName = fileName
+	session.record.SampleRate = noiseConfig.SampleRate
+	session.record.Channels = noiseConfig.Channels
+
+	repository.NewRecordingSessionRepository(a.unSyncedDB).
+		SaveRecordingSession(session.record)
+
+	a.audioSequencer.SetFramesCallback(func(frame []byte) {
+		if err := writer.Write(frame); err != nil {
+			slog.Error("Failed to write the session audio", "error", err)
+		}
+	})
+
+	return nil
+}
+
+func (a *App) stopSessionAudio(session *recordingSession) {
+	if session.audioWriter == nil {
+		return
+	}
+
+	a.audioSequencer.SetFramesCallback(nil)
+
+	if err := session.audioWriter.Close(); err != nil {
+		slog.Error("Failed to close the session audio", "error", err)
+	}
+
+	session.record.Duration = session.audioWriter.Duration().Milliseconds()
+	session.record.FileSize = session.audioWriter.Size()
+}
+
+func (a *App) getRecordingFilePath(recording *repository.RecordingSession) string {
+	return filepath.Join(filesystem.HOME_DIR, RECORDINGS_DIR, filepath.Base(recording.FileName))
+}
+
+// --- Recordings ---
+
+func (a *App) GetRecordingSessions(pageID string) []repository.RecordingSession {
+	return repository.NewRecordingSessionRepository(a.unSyncedDB).
+		GetRecordingSessions(pageID)
+}
+
+// GetRecordingSessionURL returns the URL the frontend can use to play the recording
+func (a *App) GetRecordingSessionURL(ID uint) string {
+	return fmt.Sprintf("%s%d.wav", RECORDINGS_URL_PREFIX, ID)
+}
+
+// ExportRecordingSession asks the user for a destination and copies the recording there.
+// It returns the chosen path, or an empty string if the user cancelled.
+func (a *App) ExportRecordingSession(ID uint) (string, error) {
+	recording := repository.NewRecordingSessionRepository(a.unSyncedDB).
+		GetRecordingSession(ID)
+	if recording == nil || recording.FileName == "" {
+		return "", fmt.Errorf("recording not found")
+	}
+
+	destination, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export recording",
+		DefaultFilename: fmt.Sprintf("recording-%s.wav", recording.StartedAt.Format("2006-01-02-150405")),
+		Filters: []runtime.FileFilter{
+			{DisplayName: "WAV audio (*.wav)", Pattern: "*.wav"},
+		},
+	})
+	if err != nil || destination == "" {
+		return "", err
+	}
+
+	source, err := os.Open(a.getRecordingFilePath(recording))
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	target, err := os.Create(destination)
+	if err != nil {
+		return "", err
+	}
+	defer target.Close()
+
+	if _, err := io.Copy(target, source); err != nil {
+		return "", err
+	}
+
+	return destination, nil
+}
+
+// ExportRecordingCaptions asks the user for a destination and writes the timed transcript
+// of the session there as SRT or WebVTT captions.
+// It returns the chosen path, or an empty string if the user cancelled.
+func (a *App) ExportRecordingCaptions(ID uint, options captions.Options) (string, error) {
+	recording := repository.NewRecordingSessionRepository(a.unSyncedDB).
+		GetRecordingSession(ID)
+	if recording == nil {
+		return "", fmt.Errorf("recording not found")
+	}
+
+	if len(recording.Transcript) == 0 {
+		return "", fmt.Errorf("the session has no transcript")
+	}
+
+	var segments []captions.Segment
+	if err := json.Unmarshal(recording.Transcript, &segments); err != nil {
+		return "", err
+	}
+
+	if options.Format == "" {
+		options.Format = captions.FORMAT_SRT
+	}
+	if options.Format != captions.FORMAT_SRT && options.Format != captions.FORMAT_VTT {
+		return "", fmt.Errorf("unsupported caption format: %s", options.Format)
+	}
+
+	filter := runtime.FileFilter{DisplayName: "SubRip captions (*.srt)", Pattern: "*.srt"}
+	if options.Format == captions.FORMAT_VTT {
+		filter = runtime.FileFilter{DisplayName: "WebVTT captions (*.vtt)", Pattern: "*.vtt"}
+	}
+
+	destination, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export captions",
+		DefaultFilename: fmt.Sprintf("recording-%s.%s", recording.StartedAt.Format("2006-01-02-150405"), options.Format),
+		Filters:         []runtime.FileFilter{filter},
+	})
+	if err != nil || destination == "" {
+		return "", err
+	}
+
+	target, err := os.Create(destination)
+	if err != nil {
+		return "", err
+	}
+	defer target.Close()
+
+	if err := captions.Write(target, captions.Build(segments, options), options.Format); err != nil {
+		return "", err
+	}
+
+	return destination, nil
+}
+
+func (a *App) DeleteRecordingSession(ID uint) error {
+	recordingRepository := repository.NewRecordingSessionRepository(a.unSyncedDB)
+
+	recording := recordingRepository.GetRecordingSession(ID)
+	if recording == nil {
+		return nil
+	}
+
+	if recording.FileName != "" {
+		if err := os.Remove(a.getRecordingFilePath(recording)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	recordingRepository.DeleteRecordingSession(ID)
+
+	return nil
+}
+
+// recordingsHandler serves the recordings files to the frontend
+func (a *App) recordingsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, RECORDINGS_URL_PREFIX) {
+			http.NotFound(w, r)
+			return
+		}
+
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, RECORDINGS_URL_PREFIX), ".wav")
+
+		ID, err := strconv.ParseUint(name, 10, 64)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		recording := repository.NewRecordingSessionRepository(a.unSyncedDB).
+			GetRecordingSession(uint(ID))
+		if recording == nil || recording.FileName == "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "audio/wav")
+		http.ServeFile(w, r, a.getRecordingFilePath(recording))
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"myscript/internal/updater"
 	"myscript/internal/utils"
 	"myscript/internal/utils/microphone"
+	"sync"
 
 	"gorm.io/gorm"
 )
@@ -25,6 +26,14 @@ type App struct {
 	lwt            *local_whisper.LocalWhisperTranscriber
 	updater        *updater.Updater
 	synchronizer   *Synchronizer
+
+	// Current recording session
+	session   *recordingSession
+	sessionMu sync.Mutex
+
+	// Script being read, aligned with the transcripts
+	alignment   *scriptAlignment
+	alignmentMu sync.Mutex
 }
 
 type Synchronizer struct {
@@ -107,6 +116,8 @@ func NewApp(options ...AppOption) *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+
+	go a.scheduleTrashPurge()
 }
 
 func (a *App) GetAppVersion() string {
@@ -17,8 +17,10 @@ export function ScriptReaderControllers(props: React.ComponentProps<"div">) {
     languageCode: string,
     micInputDeviceID: number[]
   ) => {
+    const pageId = String(activePageStore.getPageId() ?? "");
+
     transcriberStore
-      .startRecording(languageCode, micInputDeviceID)
+      .startRecording(pageId, languageCode, micInputDeviceID)
       .catch((err) => {
         console.error("Error starting recording:", err);
         toast.error(err || "Error starting recording");
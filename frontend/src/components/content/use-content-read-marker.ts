@@ -1,4 +1,3 @@
-import levenshtein from "damerau-levenshtein";
 import {
   cleanMarkTags,
   createTreeTextWalker,
@@ -8,43 +7,13 @@ import { useActivePageStore } from "@/store/active-page";
 import { useTranscriberStore } from "@/store/transcriber";
 import { useCallback, useEffect, useRef } from "react";
 import { Queue } from "@/lib/queue";
-import { splitWithDelimiters } from "@/lib/utils";
 import { useContentReadStore } from "@/store/content-read";
+import { useScriptAlignmentStore } from "@/store/script-alignment";
 import { toast } from "sonner";
+import { alignment } from "~wails/models";
 
 const queue = new Queue(1);
 
-const minSimilarity = 0.79;
-const fullStringMinSimilarity = 0.6;
-
-const chunkSplitter = /[^\p{L}\p{M}]+/gu;
-const iChunkSplitter = /[\p{L}\p{M}]+/gu;
-
-const similar = (v: string, w: string, similarity: number = minSimilarity) => {
-  const lev = levenshtein(v.trim().toLowerCase(), w.trim().toLowerCase());
-
-  return {
-    isSimilar: lev.similarity >= similarity,
-    levenshtein: lev,
-  };
-};
-
-const fullStringSimilar = (v: string, w: string) => {
-  v = v.replace(chunkSplitter, "").trim().toLowerCase();
-  w = w.replace(chunkSplitter, "").trim().toLowerCase();
-
-  const lev = levenshtein(v, w);
-
-  return {
-    isSimilar: lev.similarity >= fullStringMinSimilarity,
-    levenshtein: lev,
-  };
-};
-
-function cleanUp(str: string) {
-  return str.replace(/\[.*?\]/g, "").trim();
-}
-
 function scrollToLastMarker() {
   const element = Array.from(
     document.getElementsByClassName("screen-reader-marker")
@@ -55,21 +24,46 @@ function scrollToLastMarker() {
   }
 }
 
-type MatchedNode = { node: Node; start: number; end: number };
+/**
+ * Text of the page as read by the script alignment, the offsets of its matches
+ * are counted on the same text nodes as the marker position
+ */
+function getContainerText(container: Node) {
+  const treeWalker = createTreeTextWalker(container);
+
+  let text = "";
+  while (treeWalker.nextNode()) {
+    text += treeWalker.currentNode.nodeValue || "";
+  }
+
+  return text;
+}
+
+function scrollToPosition(container: Node, offset: number) {
+  const treeWalker = createTreeTextWalker(container);
 
-type MatchedChunk = {
-  node: Node;
-  similarity: number;
-  matchedString: string;
-  matchStartPosition: number;
-  matchEndPosition: number;
-  position: number;
-};
+  let position = 0;
+  while (treeWalker.nextNode()) {
+    const node = treeWalker.currentNode;
+    position += node.nodeValue?.length || 0;
+
+    if (position > offset) {
+      node.parentElement?.scrollIntoView({
+        behavior: "smooth",
+        block: "center",
+      });
+      return;
+    }
+  }
+}
+
+type MatchedNode = { node: Node; start: number; end: number };
 
 export function useContentReadMarker() {
   const transcriberStore = useTranscriberStore();
   const activePageStore = useActivePageStore();
   const contentReadStore = useContentReadStore();
+  const scriptAlignmentStore = useScriptAlignmentStore();
 
   const totalContentLength = useRef<number>(0);
   const lastMarkerPosition = useRef<number>(0);
@@ -78,204 +72,6 @@ export function useContentReadMarker() {
 
   const relocatingMarkerPosition = useRef<boolean>(false);
 
-  /**
-   * This function will try to match the transcribed text with the current page content using levenshtein distance
-   * If a match is found, it will surround the matched content with a span element (a marker)
-   */
-  const onTranscribedText = useCallback((text: string) => {
-    if (!containerRef.current || relocatingMarkerPosition.current) return;
-
-    let position = 0;
-
-    let chunks = text.trim().split(chunkSplitter).filter(Boolean);
-    let splitter = text.trim().replace(iChunkSplitter, "").length;
-
-    let matchedString = "";
-
-    let matchingIteration = 0;
-    let minChunksIteration = chunks.length - Math.round(splitter / 2);
-    let maxChunksIteration = chunks.length + Math.round(splitter / 2);
-
-    console.table({
-      text,
-      minChunksIteration,
-      maxChunksIteration,
-    });
-
-    let startMatched = false;
-    let endMatched = false;
-
-    let matchedNodes: MatchedNode[] = [];
-    let matchedChunks: MatchedChunk[] = [];
-
-    const treeWalker = createTreeTextWalker(containerRef.current);
-
-    while (treeWalker.nextNode() && chunks.length > 0) {
-      const node = treeWalker.currentNode;
-      const nodeValueLength = node.nodeValue?.length || 0;
-
-      let matchStartPosition = 0;
-      let matchEndPosition = nodeValueLength;
-
-      if (lastMarkerPosition.current >= position + nodeValueLength) {
-        position += nodeValueLength;
-        continue;
-      } else if (
-        lastMarkerPosition.current < position + nodeValueLength &&
-        lastMarkerPosition.current > position
-      ) {
-        matchStartPosition = lastMarkerPosition.current - position;
-      }
-
-      const nodeValue = node.nodeValue?.slice(matchStartPosition);
-      const nodeValueChunks =
-        splitWithDelimiters(nodeValue || "", chunkSplitter) || [];
-
-      if (nodeValue?.trim() === "") {
-        position += nodeValueLength;
-        continue;
-      }
-
-      const arr = nodeValueChunks;
-      for (let i = 0; i < arr.length; i++) {
-        const chunk = arr[i];
-        const chunkDelimiter = chunk.delimiter || "";
-
-        // Try to find a start match so we can start similarity calculation
-        if (!startMatched && matchingIteration <= minChunksIteration) {
-          const isSimilar = () => {
-            const i1 = similar(chunks[0], chunk.text).isSimilar;
-
-            return (
-              i1 || (!!chunks[1] && similar(chunks[1], chunk.text).isSimilar)
-            );
-          };
-
-          if (isSimilar()) {
-            // Reset matched chunks on start matched
-            matchedChunks = [];
-
-            console.log("%cStart matched", "color: green");
-            console.table({
-              chunk: chunk.text,
-              maxChunksIteration,
-            });
-
-            startMatched = true;
-          }
-        }
-
-        if (startMatched) {
-          matchingIteration++;
-          matchedString += chunk.text + chunkDelimiter;
-        }
-
-        if (
-          startMatched &&
-          matchingIteration >= minChunksIteration &&
-          matchingIteration <= maxChunksIteration
-        ) {
-          const { isSimilar, levenshtein } = fullStringSimilar(
-            matchedString,
-            text
-          );
-
-          if (isSimilar) {
-            const $matchedEndPosition = arr.reduce((acc, v, ii) => {
-              if (ii <= i) {
-                acc += v.text.length + (v.delimiter?.length || 0);
-              }
-              return acc;
-            }, 0);
-
-            matchedChunks.push({
-              node,
-              similarity: levenshtein.similarity,
-              matchedString,
-              matchStartPosition,
-              position: position + $matchedEndPosition,
-              matchEndPosition: $matchedEndPosition,
-            });
-
-            if (matchingIteration === maxChunksIteration) {
-              matchEndPosition = $matchedEndPosition;
-            }
-          }
-
-          if (matchingIteration === maxChunksIteration) {
-            if (matchedChunks.length > 0) {
-              endMatched = true;
-
-              console.log("%cEnd matched", "color: blue");
-              console.table({
-                matchedString,
-                chunk: chunk.text,
-              });
-            } else {
-              matchedChunks = [];
-              matchingIteration = 0;
-              matchedString = "";
-              startMatched = false;
-              endMatched = false;
-            }
-          }
-        }
-
-        if (startMatched && endMatched) {
-          break;
-        }
-      }
-
-      matchedNodes.push({
-        node,
-        start: matchStartPosition,
-        end: matchEndPosition,
-      });
-
-      position += matchEndPosition;
-
-      if (startMatched && endMatched) {
-        break;
-      }
-
-      // This should come startMatched and endMatched condition (The one above)
-      if (matchingIteration > maxChunksIteration) {
-        matchedChunks = [];
-        matchingIteration = 0;
-        startMatched = false;
-        endMatched = false;
-      }
-    }
-
-    if (matchedChunks.length > 0) {
-      const bestChunk = matchedChunks.reduce((acc, v) => {
-        if (acc.similarity < v.similarity) {
-          return v;
-        }
-        return acc;
-      });
-
-      const nodes: MatchedNode[] = [];
-
-      for (let match of matchedNodes) {
-        if (match.node === bestChunk.node) {
-          match.start = bestChunk.matchStartPosition;
-          match.end = bestChunk.matchEndPosition;
-          nodes.push(match);
-          break;
-        } else {
-          nodes.push(match);
-        }
-      }
-
-      lastMarkerPosition.current = bestChunk.position;
-
-      nodes.forEach((node) => {
-        surroundContentsTag(node.node, node.start, node.end);
-      });
-    }
-  }, []);
-
   const setTotalContentLength = useCallback(() => {
     if (!containerRef.current) return;
 
@@ -339,6 +135,19 @@ export function useContentReadMarker() {
     });
   }, []);
 
+  /**
+   * Moves the marker to the end of the script text matched by the alignment.
+   * The marker is drawn again, as the reader may have gone back in the script.
+   */
+  const onScriptAligned = useCallback((match: alignment.Match) => {
+    if (!containerRef.current || relocatingMarkerPosition.current) return;
+
+    cleanMarkTags(containerRef.current);
+
+    lastMarkerPosition.current = match.end;
+    moveMarkerToLastPosition();
+  }, []);
+
   const onTranscriptionProgress = useCallback(() => {
     const pageId = activePageStore.getPageId();
 
@@ -422,6 +231,7 @@ export function useContentReadMarker() {
 
     lastMarkerPosition.current = position;
     moveMarkerToLastPosition();
+    scriptAlignmentStore.setScriptAlignmentOffset(position);
 
     relocatingMarkerPosition.current = false;
     selection.removeAllRanges();
@@ -443,6 +253,7 @@ export function useContentReadMarker() {
         }
         lastMarkerPosition.current = progress.progress;
         moveMarkerToLastPosition();
+        scriptAlignmentStore.setScriptAlignmentOffset(progress.progress);
       });
     }
   }, [activePageStore.readMode]);
@@ -453,26 +264,68 @@ export function useContentReadMarker() {
     }
   }, [activePageStore.readMode]);
 
+  // Align the transcripts with the page text while recording
   useEffect(() => {
-    return transcriberStore.onTranscribedText((text) => {
-      text = cleanUp(text);
+    const languageCode = transcriberStore.languageCode;
+
+    if (
+      !activePageStore.readMode ||
+      !transcriberStore.isRecording ||
+      !languageCode ||
+      !containerRef.current
+    ) {
+      return;
+    }
+
+    scriptAlignmentStore
+      .startScriptAlignment(
+        getContainerText(containerRef.current),
+        languageCode,
+        lastMarkerPosition.current
+      )
+      .catch((err) => {
+        console.error("Error starting the script alignment:", err);
+      });
+
+    return () => {
+      scriptAlignmentStore.stopScriptAlignment();
+    };
+  }, [activePageStore.readMode, transcriberStore.isRecording]);
 
+  useEffect(() => {
+    return scriptAlignmentStore.onScriptAligned((match) => {
       queue.task(() => {
         return new Promise<null>((resolve) => {
           requestAnimationFrame(() => {
-            console.groupCollapsed("New transcription...");
-
-            onTranscribedText(text);
+            onScriptAligned(match);
             scrollToLastMarker();
             onTranscriptionProgress();
             resolve(null);
-
-            console.groupEnd();
           });
         });
       });
     });
-  }, [onTranscribedText]);
+  }, [onScriptAligned]);
+
+  // Keep the predicted position in view between two aligned transcripts
+  useEffect(() => {
+    return scriptAlignmentStore.onScriptPredicted((prediction) => {
+      if (
+        !containerRef.current ||
+        prediction.start <= lastMarkerPosition.current
+      ) {
+        return;
+      }
+
+      scrollToPosition(containerRef.current, prediction.start);
+    });
+  }, []);
+
+  useEffect(() => {
+    return scriptAlignmentStore.onCueReached((cue) => {
+      toast.info(cue.text);
+    });
+  }, []);
 
   return { containerRef, moveMarker };
 }
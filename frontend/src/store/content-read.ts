@@ -1,5 +1,5 @@
 import { create } from "zustand";
-import { GetCache, SaveCache } from "~wails/main/App";
+import { GetReadProgress, SaveReadProgress } from "~wails/main/App";
 
 type ContentReadState = {
   resume: boolean;
@@ -25,13 +25,15 @@ export const useContentReadStore = create<ContentReadState>((set) => ({
   },
 
   setContentReadProgress: async (pageId, progress, total) => {
-    await SaveCache(`page-${pageId}-read-progress`, { progress, total });
+    await SaveReadProgress(String(pageId), progress, total);
   },
 
   getContentReadProgress: async (pageId) => {
-    const cacheKey = `page-${pageId}-read-progress`;
-    const cache = await GetCache(cacheKey);
+    const readProgress = await GetReadProgress(String(pageId));
 
-    return cache?.value || { progress: 0, total: 0 };
+    return {
+      progress: readProgress?.progress || 0,
+      total: readProgress?.total || 0,
+    };
   },
 }));
@@ -0,0 +1,70 @@
+import { EventClear } from "@/types";
+import { create } from "zustand";
+import { EventsOn } from "~wails-runtime";
+import {
+  GetScriptCues,
+  SetScriptAlignmentOffset,
+  StartScriptAlignment,
+  StopScriptAlignment,
+} from "~wails/main/App";
+import { alignment, cues } from "~wails/models";
+
+// Predicted reading position, pushed between two aligned transcripts
+export type ScriptPrediction = {
+  word: number;
+  start: number;
+  end: number;
+  words_per_minute: number;
+};
+
+type ScriptAlignmentState = {
+  startScriptAlignment: (
+    text: string,
+    languageCode: string,
+    offset: number
+  ) => Promise<void>;
+  setScriptAlignmentOffset: (offset: number) => Promise<void>;
+  stopScriptAlignment: () => Promise<void>;
+  // Cues of the text given to startScriptAlignment, at their offsets in it
+  getScriptCues: (text: string, languageCode: string) => Promise<cues.Cue[]>;
+
+  onScriptAligned: (callback: (match: alignment.Match) => void) => EventClear;
+  onScriptPredicted: (
+    callback: (prediction: ScriptPrediction) => void
+  ) => EventClear;
+  onCueReached: (callback: (cue: cues.Cue) => void) => EventClear;
+};
+
+const ON_SCRIPT_ALIGNED = "on-script-aligned";
+const ON_SCRIPT_PREDICTED = "on-script-predicted";
+const ON_CUE_REACHED = "on-cue-reached";
+
+export const useScriptAlignmentStore = create<ScriptAlignmentState>(() => ({
+  startScriptAlignment: (text, languageCode, offset) => {
+    return StartScriptAlignment(text, languageCode, offset);
+  },
+
+  setScriptAlignmentOffset: (offset) => {
+    return SetScriptAlignmentOffset(offset);
+  },
+
+  stopScriptAlignment: () => {
+    return StopScriptAlignment();
+  },
+
+  getScriptCues: (text, languageCode) => {
+    return GetScriptCues(text, languageCode);
+  },
+
+  onScriptAligned(callback) {
+    return EventsOn(ON_SCRIPT_ALIGNED, callback);
+  },
+
+  onScriptPredicted(callback) {
+    return EventsOn(ON_SCRIPT_PREDICTED, callback);
+  },
+
+  onCueReached(callback) {
+    return EventsOn(ON_CUE_REACHED, callback);
+  },
+}));
@@ -14,10 +14,13 @@ import { EventClear } from "@/types";
 
 type TranscriberState = {
   isRecording: boolean;
+  // Language of the current recording
+  languageCode: string | null;
   languages: Array<structs.Language>;
   micInputDevices: Array<microphone.MicInputDevice>;
 
   startRecording: (
+    pageId: string,
     languageCode: string,
     micInputDeviceID: number[]
   ) => Promise<void>;
@@ -45,14 +48,17 @@ const ON_RECORDING_STOPPED = "on-recording-stopped";
 
 export const useTranscriberStore = create<TranscriberState>((set, get) => ({
   isRecording: false,
+  languageCode: null,
   languages: [],
   micInputDevices: [],
 
-  async startRecording(languageCode, micInputDeviceID) {
+  async startRecording(pageId, languageCode, micInputDeviceID) {
     if (!get().isRecording) {
       const micDeviceID = JSON.stringify(micInputDeviceID);
 
-      return StartRecording(languageCode, micDeviceID).finally(() => {
+      set({ languageCode });
+
+      return StartRecording(pageId, languageCode, micDeviceID).finally(() => {
         get().getRecordingStatus();
       });
     }
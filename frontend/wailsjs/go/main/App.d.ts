@@ -1,18 +1,28 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {database} from '../models';
-import {local_whisper} from '../models';
+import {alignment} from '../models';
+import {microphone} from '../models';
 import {repository} from '../models';
+import {local_whisper} from '../models';
+import {captions} from '../models';
 import {structs} from '../models';
 import {whisper} from '../models';
-import {microphone} from '../models';
 import {notion} from '../models';
 import {notionapi} from '../models';
+import {cues} from '../models';
+import {importer} from '../models';
 
 export function AffectedTablesPlaceholder():Promise<database.AffectedTables>;
 
+export function AlignTranscript(arg1:string):Promise<alignment.Match>;
+
 export function AreSomeLocalWhisperModelsDownloading():Promise<boolean>;
 
+export function CalibrateMicrophone(arg1:string,arg2:number):Promise<microphone.CalibrationResult>;
+
+export function CalibrateMicrophoneSpeech(arg1:string,arg2:number):Promise<microphone.CalibrationResult>;
+
 export function CheckForUpdates():Promise<string>;
 
 export function DeleteCache(arg1:string):Promise<void>;
@@ -21,18 +31,40 @@ export function DeleteGoogleAuthToken():Promise<void>;
 
 export function DeleteLocalPage(arg1:string):Promise<void>;
 
+export function DeletePageRevision(arg1:string):Promise<void>;
+
+export function DeleteRecordingSession(arg1:number):Promise<void>;
+
+export function DevStartFileReplay(arg1:string,arg2:string,arg3:string,arg4:number):Promise<void>;
+
+export function DiffPageRevisions(arg1:string,arg2:string):Promise<repository.PageRevisionDiff>;
+
 export function DownloadLocalWhisperModels(arg1:Array<local_whisper.LocalWhisperModel>):Promise<void>;
 
+export function DuplicateLocalPage(arg1:string):Promise<repository.Page>;
+
+export function EmptyTrash():Promise<void>;
+
 export function ExistsLocalWhisperModel(arg1:local_whisper.LocalWhisperModel):Promise<boolean>;
 
+export function ExportMarkdown(arg1:string):Promise<string>;
+
+export function ExportRecordingCaptions(arg1:number,arg2:captions.Options):Promise<string>;
+
+export function ExportRecordingSession(arg1:number):Promise<string>;
+
 export function GetAppVersion():Promise<string>;
 
+export function GetAudioLevelPlaceholder():Promise<microphone.AudioLevel>;
+
 export function GetBestLocalWhisperModel():Promise<string>;
 
 export function GetCache(arg1:string):Promise<repository.CacheValue>;
 
 export function GetConfig():Promise<repository.Config>;
 
+export function GetCoverageReport(arg1:number):Promise<alignment.CoverageReport>;
+
 export function GetGoogleAuthToken():Promise<repository.GoogleAuthToken>;
 
 export function GetLanguages():Promise<Array<structs.Language>>;
@@ -47,16 +79,46 @@ export function GetLocalWhisperModels():Promise<Array<whisper.WhisperModel>>;
 
 export function GetMicInputDevices():Promise<Array<microphone.MicInputDevice>>;
 
+export function GetMicrophoneSetting(arg1:string):Promise<repository.MicrophoneSetting>;
+
 export function GetNotionPageBlocks(arg1:string):Promise<Array<notion.NotionBlock>>;
 
 export function GetNotionPages():Promise<Array<notionapi.Object>>;
 
+export function GetPageReadingStats(arg1:string):Promise<repository.ReadingStats>;
+
+export function GetPageRevision(arg1:string):Promise<repository.PageRevision>;
+
+export function GetPageRevisions(arg1:string):Promise<Array<repository.PageRevision>>;
+
+export function GetReadProgress(arg1:string):Promise<repository.ReadProgress>;
+
+export function GetReadingSessions(arg1:string):Promise<Array<repository.ReadingSession>>;
+
+export function GetReadingStats():Promise<Array<repository.ReadingStats>>;
+
+export function GetRecordingSessionURL(arg1:number):Promise<string>;
+
+export function GetRecordingSessions(arg1:string):Promise<Array<repository.RecordingSession>>;
+
+export function GetScriptCues(arg1:string,arg2:string):Promise<Array<cues.Cue>>;
+
+export function GetTrashedPages():Promise<Array<repository.Page>>;
+
+export function GetVoiceDetectors():Promise<Array<string>>;
+
 export function GetWhisperLanguages():Promise<Array<structs.Language>>;
 
 export function GetWitAILanguages():Promise<Array<structs.Language>>;
 
 export function GroqTranscribe(arg1:Array<number>,arg2:string):Promise<string>;
 
+export function ImportDocuments(arg1:any):Promise<Array<importer.Result>>;
+
+export function ImportMarkdownDirectory(arg1:any):Promise<repository.Page>;
+
+export function ImportMarkdownFiles(arg1:any):Promise<Array<repository.Page>>;
+
 export function IsDevMode():Promise<boolean>;
 
 export function IsGoogleAuthEnabled():Promise<boolean>;
@@ -65,28 +127,56 @@ export function IsLocalWhisperModelDownloading(arg1:local_whisper.LocalWhisperMo
 
 export function IsRecording():Promise<boolean>;
 
+export function IsRecordingPaused():Promise<boolean>;
+
 export function LocalTranscribe(arg1:Array<number>,arg2:string):Promise<string>;
 
+export function MoveLocalPage(arg1:string,arg2:any,arg3:number):Promise<void>;
+
 export function OpenAITranscribe(arg1:Array<number>,arg2:string):Promise<string>;
 
+export function PauseRecording():Promise<void>;
+
 export function PerformUpdate():Promise<void>;
 
+export function PurgeLocalPage(arg1:string):Promise<void>;
+
 export function RefreshGoogleAuthToken():Promise<repository.GoogleAuthToken>;
 
+export function ResetMicrophoneSetting(arg1:string):Promise<void>;
+
+export function RestoreLocalPage(arg1:string):Promise<repository.Page>;
+
+export function RestorePageRevision(arg1:string):Promise<repository.Page>;
+
+export function ResumeRecording():Promise<void>;
+
 export function SaveCache(arg1:string,arg2:any):Promise<repository.Cache>;
 
 export function SaveConfig(arg1:repository.Config):Promise<repository.Config>;
 
 export function SaveLocalPage(arg1:repository.Page):Promise<repository.Page>;
 
+export function SaveMicrophoneSetting(arg1:repository.MicrophoneSetting):Promise<repository.MicrophoneSetting>;
+
+export function SaveReadProgress(arg1:string,arg2:number,arg3:number):Promise<repository.ReadProgress>;
+
+export function SearchPages(arg1:string):Promise<Array<repository.PageSearchResult>>;
+
+export function SetScriptAlignmentOffset(arg1:number):Promise<void>;
+
 export function StartGoogleAuthorization():Promise<void>;
 
-export function StartRecording(arg1:string,arg2:string):Promise<void>;
+export function StartRecording(arg1:string,arg2:string,arg3:string):Promise<void>;
+
+export function StartScriptAlignment(arg1:string,arg2:string,arg3:number):Promise<void>;
 
 export function StartSynchronizer():Promise<void>;
 
 export function StopRecording():Promise<void>;
 
+export function StopScriptAlignment():Promise<void>;
+
 export function StopSynchronizer():Promise<void>;
 
 export function Transcribe(arg1:Array<number>,arg2:string):Promise<string>;
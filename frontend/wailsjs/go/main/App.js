@@ -6,10 +6,22 @@ export function AffectedTablesPlaceholder() {
   return window['go']['main']['App']['AffectedTablesPlaceholder']();
 }
 
+export function AlignTranscript(arg1) {
+  return window['go']['main']['App']['AlignTranscript'](arg1);
+}
+
 export function AreSomeLocalWhisperModelsDownloading() {
   return window['go']['main']['App']['AreSomeLocalWhisperModelsDownloading']();
 }
 
+export function CalibrateMicrophone(arg1, arg2) {
+  return window['go']['main']['App']['CalibrateMicrophone'](arg1, arg2);
+}
+
+export function CalibrateMicrophoneSpeech(arg1, arg2) {
+  return window['go']['main']['App']['CalibrateMicrophoneSpeech'](arg1, arg2);
+}
+
 export function CheckForUpdates() {
   return window['go']['main']['App']['CheckForUpdates']();
 }
@@ -26,18 +38,58 @@ export function DeleteLocalPage(arg1) {
   return window['go']['main']['App']['DeleteLocalPage'](arg1);
 }
 
+export function DeletePageRevision(arg1) {
+  return window['go']['main']['App']['DeletePageRevision'](arg1);
+}
+
+export function DeleteRecordingSession(arg1) {
+  return window['go']['main']['App']['DeleteRecordingSession'](arg1);
+}
+
+export function DevStartFileReplay(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['DevStartFileReplay'](arg1, arg2, arg3, arg4);
+}
+
+export function DiffPageRevisions(arg1, arg2) {
+  return window['go']['main']['App']['DiffPageRevisions'](arg1, arg2);
+}
+
 export function DownloadLocalWhisperModels(arg1) {
   return window['go']['main']['App']['DownloadLocalWhisperModels'](arg1);
 }
 
+export function DuplicateLocalPage(arg1) {
+  return window['go']['main']['App']['DuplicateLocalPage'](arg1);
+}
+
+export function EmptyTrash() {
+  return window['go']['main']['App']['EmptyTrash']();
+}
+
 export function ExistsLocalWhisperModel(arg1) {
   return window['go']['main']['App']['ExistsLocalWhisperModel'](arg1);
 }
 
+export function ExportMarkdown(arg1) {
+  return window['go']['main']['App']['ExportMarkdown'](arg1);
+}
+
+export function ExportRecordingCaptions(arg1, arg2) {
+  return window['go']['main']['App']['ExportRecordingCaptions'](arg1, arg2);
+}
+
+export function ExportRecordingSession(arg1) {
+  return window['go']['main']['App']['ExportRecordingSession'](arg1);
+}
+
 export function GetAppVersion() {
   return window['go']['main']['App']['GetAppVersion']();
 }
 
+export function GetAudioLevelPlaceholder() {
+  return window['go']['main']['App']['GetAudioLevelPlaceholder']();
+}
+
 export function GetBestLocalWhisperModel() {
   return window['go']['main']['App']['GetBestLocalWhisperModel']();
 }
@@ -50,6 +102,10 @@ export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetCoverageReport(arg1) {
+  return window['go']['main']['App']['GetCoverageReport'](arg1);
+}
+
 export function GetGoogleAuthToken() {
   return window['go']['main']['App']['GetGoogleAuthToken']();
 }
@@ -78,6 +134,10 @@ export function GetMicInputDevices() {
   return window['go']['main']['App']['GetMicInputDevices']();
 }
 
+export function GetMicrophoneSetting(arg1) {
+  return window['go']['main']['App']['GetMicrophoneSetting'](arg1);
+}
+
 export function GetNotionPageBlocks(arg1) {
   return window['go']['main']['App']['GetNotionPageBlocks'](arg1);
 }
@@ -86,6 +146,50 @@ export function GetNotionPages() {
   return window['go']['main']['App']['GetNotionPages']();
 }
 
+export function GetPageReadingStats(arg1) {
+  return window['go']['main']['App']['GetPageReadingStats'](arg1);
+}
+
+export function GetPageRevision(arg1) {
+  return window['go']['main']['App']['GetPageRevision'](arg1);
+}
+
+export function GetPageRevisions(arg1) {
+  return window['go']['main']['App']['GetPageRevisions'](arg1);
+}
+
+export function GetReadProgress(arg1) {
+  return window['go']['main']['App']['GetReadProgress'](arg1);
+}
+
+export function GetReadingSessions(arg1) {
+  return window['go']['main']['App']['GetReadingSessions'](arg1);
+}
+
+export function GetReadingStats() {
+  return window['go']['main']['App']['GetReadingStats']();
+}
+
+export function GetRecordingSessionURL(arg1) {
+  return window['go']['main']['App']['GetRecordingSessionURL'](arg1);
+}
+
+export function GetRecordingSessions(arg1) {
+  return window['go']['main']['App']['GetRecordingSessions'](arg1);
+}
+
+export function GetScriptCues(arg1, arg2) {
+  return window['go']['main']['App']['GetScriptCues'](arg1, arg2);
+}
+
+export function GetTrashedPages() {
+  return window['go']['main']['App']['GetTrashedPages']();
+}
+
+export function GetVoiceDetectors() {
+  return window['go']['main']['App']['GetVoiceDetectors']();
+}
+
 export function GetWhisperLanguages() {
   return window['go']['main']['App']['GetWhisperLanguages']();
 }
@@ -98,6 +202,18 @@ export function GroqTranscribe(arg1, arg2) {
   return window['go']['main']['App']['GroqTranscribe'](arg1, arg2);
 }
 
+export function ImportDocuments(arg1) {
+  return window['go']['main']['App']['ImportDocuments'](arg1);
+}
+
+export function ImportMarkdownDirectory(arg1) {
+  return window['go']['main']['App']['ImportMarkdownDirectory'](arg1);
+}
+
+export function ImportMarkdownFiles(arg1) {
+  return window['go']['main']['App']['ImportMarkdownFiles'](arg1);
+}
+
 export function IsDevMode() {
   return window['go']['main']['App']['IsDevMode']();
 }
@@ -114,22 +230,54 @@ export function IsRecording() {
   return window['go']['main']['App']['IsRecording']();
 }
 
+export function IsRecordingPaused() {
+  return window['go']['main']['App']['IsRecordingPaused']();
+}
+
 export function LocalTranscribe(arg1, arg2) {
   return window['go']['main']['App']['LocalTranscribe'](arg1, arg2);
 }
 
+export function MoveLocalPage(arg1, arg2, arg3) {
+  return window['go']['main']['App']['MoveLocalPage'](arg1, arg2, arg3);
+}
+
 export function OpenAITranscribe(arg1, arg2) {
   return window['go']['main']['App']['OpenAITranscribe'](arg1, arg2);
 }
 
+export function PauseRecording() {
+  return window['go']['main']['App']['PauseRecording']();
+}
+
 export function PerformUpdate() {
   return window['go']['main']['App']['PerformUpdate']();
 }
 
+export function PurgeLocalPage(arg1) {
+  return window['go']['main']['App']['PurgeLocalPage'](arg1);
+}
+
 export function RefreshGoogleAuthToken() {
   return window['go']['main']['App']['RefreshGoogleAuthToken']();
 }
 
+export function ResetMicrophoneSetting(arg1) {
+  return window['go']['main']['App']['ResetMicrophoneSetting'](arg1);
+}
+
+export function RestoreLocalPage(arg1) {
+  return window['go']['main']['App']['RestoreLocalPage'](arg1);
+}
+
+export function RestorePageRevision(arg1) {
+  return window['go']['main']['App']['RestorePageRevision'](arg1);
+}
+
+export function ResumeRecording() {
+  return window['go']['main']['App']['ResumeRecording']();
+}
+
 export function SaveCache(arg1, arg2) {
   return window['go']['main']['App']['SaveCache'](arg1, arg2);
 }
@@ -142,12 +290,32 @@ export function SaveLocalPage(arg1) {
   return window['go']['main']['App']['SaveLocalPage'](arg1);
 }
 
+export function SaveMicrophoneSetting(arg1) {
+  return window['go']['main']['App']['SaveMicrophoneSetting'](arg1);
+}
+
+export function SaveReadProgress(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveReadProgress'](arg1, arg2, arg3);
+}
+
+export function SearchPages(arg1) {
+  return window['go']['main']['App']['SearchPages'](arg1);
+}
+
+export function SetScriptAlignmentOffset(arg1) {
+  return window['go']['main']['App']['SetScriptAlignmentOffset'](arg1);
+}
+
 export function StartGoogleAuthorization() {
   return window['go']['main']['App']['StartGoogleAuthorization']();
 }
 
-export function StartRecording(arg1, arg2) {
-  return window['go']['main']['App']['StartRecording'](arg1, arg2);
+export function StartRecording(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartRecording'](arg1, arg2, arg3);
+}
+
+export function StartScriptAlignment(arg1, arg2, arg3) {
+  return window['go']['main']['App']['StartScriptAlignment'](arg1, arg2, arg3);
 }
 
 export function StartSynchronizer() {
@@ -158,6 +326,10 @@ export function StopRecording() {
   return window['go']['main']['App']['StopRecording']();
 }
 
+export function StopScriptAlignment() {
+  return window['go']['main']['App']['StopScriptAlignment']();
+}
+
 export function StopSynchronizer() {
   return window['go']['main']['App']['StopSynchronizer']();
 }
@@ -1,3 +1,193 @@
+export namespace alignment {
+	
+	export class SentenceCoverage {
+	    status: string;
+	    text: string;
+	    spoken: string;
+	    similarity: number;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SentenceCoverage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.text = source["text"];
+	        this.spoken = source["spoken"];
+	        this.similarity = source["similarity"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class CoverageReport {
+	    sentences: SentenceCoverage[];
+	    read: number;
+	    paraphrased: number;
+	    skipped: number;
+	    inserted: number;
+	    words_covered: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CoverageReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sentences = this.convertValues(source["sentences"], SentenceCoverage);
+	        this.read = source["read"];
+	        this.paraphrased = source["paraphrased"];
+	        this.skipped = source["skipped"];
+	        this.inserted = source["inserted"];
+	        this.words_covered = source["words_covered"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Match {
+	    start_word: number;
+	    end_word: number;
+	    start: number;
+	    end: number;
+	    confidence: number;
+	    transcript: string;
+	    script: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Match(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start_word = source["start_word"];
+	        this.end_word = source["end_word"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.confidence = source["confidence"];
+	        this.transcript = source["transcript"];
+	        this.script = source["script"];
+	    }
+	}
+
+}
+
+export namespace captions {
+	
+	export class Options {
+	    format: string;
+	    use_script: boolean;
+	    min_confidence: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Options(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.format = source["format"];
+	        this.use_script = source["use_script"];
+	        this.min_confidence = source["min_confidence"];
+	    }
+	}
+
+}
+
+export namespace cues {
+	
+	export class Cue {
+	    type: string;
+	    argument: string;
+	    text: string;
+	    word: number;
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Cue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.argument = source["argument"];
+	        this.text = source["text"];
+	        this.word = source["word"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+
+}
+
+export namespace importer {
+	
+	export class Unsupported {
+	    element: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Unsupported(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.element = source["element"];
+	        this.count = source["count"];
+	    }
+	}
+	export class Result {
+	    page: repository.Page;
+	    unsupported: Unsupported[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Result(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.page = this.convertValues(source["page"], repository.Page);
+	        this.unsupported = this.convertValues(source["unsupported"], Unsupported);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace local_whisper {
 	
 	export class DownloadProgress {
@@ -35,10 +225,49 @@ export namespace local_whisper {
 
 export namespace microphone {
 	
+	export class AudioLevel {
+	    PeakDecibels: number;
+	    RMSDecibels: number;
+	    InSpeech: boolean;
+	    BufferedDuration: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AudioLevel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.PeakDecibels = source["PeakDecibels"];
+	        this.RMSDecibels = source["RMSDecibels"];
+	        this.InSpeech = source["InSpeech"];
+	        this.BufferedDuration = source["BufferedDuration"];
+	    }
+	}
+	export class CalibrationResult {
+	    NoiseFloor: number;
+	    NoiseVariance: number;
+	    SpeechLevel?: number;
+	    NoiseThreshold: number;
+	    TriggerDecibels: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CalibrationResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.NoiseFloor = source["NoiseFloor"];
+	        this.NoiseVariance = source["NoiseVariance"];
+	        this.SpeechLevel = source["SpeechLevel"];
+	        this.NoiseThreshold = source["NoiseThreshold"];
+	        this.TriggerDecibels = source["TriggerDecibels"];
+	    }
+	}
 	export class MicInputDevice {
 	    Name: string;
 	    IsDefault: number;
 	    ID: number[];
+	    Channels: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new MicInputDevice(source);
@@ -49,6 +278,7 @@ export namespace microphone {
 	        this.Name = source["Name"];
 	        this.IsDefault = source["IsDefault"];
 	        this.ID = source["ID"];
+	        this.Channels = source["Channels"];
 	    }
 	}
 
@@ -163,6 +393,8 @@ export namespace repository {
 	    TranscriberSource: string;
 	    LocalWhisperModel?: string;
 	    LocalWhisperGPU?: boolean;
+	    RecordSessionAudio?: boolean;
+	    TrashRetentionDays?: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new Config(source);
@@ -180,6 +412,8 @@ export namespace repository {
 	        this.TranscriberSource = source["TranscriberSource"];
 	        this.LocalWhisperModel = source["LocalWhisperModel"];
 	        this.LocalWhisperGPU = source["LocalWhisperGPU"];
+	        this.RecordSessionAudio = source["RecordSessionAudio"];
+	        this.TrashRetentionDays = source["TrashRetentionDays"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -245,6 +479,78 @@ export namespace repository {
 		    return a;
 		}
 	}
+	export class MicrophoneSetting {
+	    ID: number;
+	    // Go type: time
+	    CreatedAt: any;
+	    // Go type: time
+	    UpdatedAt: any;
+	    // Go type: gorm
+	    DeletedAt: any;
+	    DeviceName: string;
+	    NoiseFloor?: number;
+	    NoiseVariance?: number;
+	    SpeechLevel?: number;
+	    // Go type: time
+	    CalibratedAt?: any;
+	    NoiseThreshold?: number;
+	    TriggerDecibels?: number;
+	    VoiceDetector?: string;
+	    CaptureChannels?: number;
+	    InputChannels?: string;
+	    HighPassFilter?: boolean;
+	    NoiseReduction?: boolean;
+	    AutoGain?: boolean;
+	    AutoGainTarget?: number;
+	    AutoFallback?: boolean;
+	    FallbackDeviceName?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new MicrophoneSetting(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.CreatedAt = this.convertValues(source["CreatedAt"], null);
+	        this.UpdatedAt = this.convertValues(source["UpdatedAt"], null);
+	        this.DeletedAt = this.convertValues(source["DeletedAt"], null);
+	        this.DeviceName = source["DeviceName"];
+	        this.NoiseFloor = source["NoiseFloor"];
+	        this.NoiseVariance = source["NoiseVariance"];
+	        this.SpeechLevel = source["SpeechLevel"];
+	        this.CalibratedAt = this.convertValues(source["CalibratedAt"], null);
+	        this.NoiseThreshold = source["NoiseThreshold"];
+	        this.TriggerDecibels = source["TriggerDecibels"];
+	        this.VoiceDetector = source["VoiceDetector"];
+	        this.CaptureChannels = source["CaptureChannels"];
+	        this.InputChannels = source["InputChannels"];
+	        this.HighPassFilter = source["HighPassFilter"];
+	        this.NoiseReduction = source["NoiseReduction"];
+	        this.AutoGain = source["AutoGain"];
+	        this.AutoGainTarget = source["AutoGainTarget"];
+	        this.AutoFallback = source["AutoFallback"];
+	        this.FallbackDeviceName = source["FallbackDeviceName"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	export class Page {
 	    ID: string;
 	    // Go type: time
@@ -300,6 +606,328 @@ export namespace repository {
 		    return a;
 		}
 	}
+	export class PageRevision {
+	    ID: string;
+	    // Go type: time
+	    CreatedAt: any;
+	    // Go type: time
+	    UpdatedAt: any;
+	    // Go type: gorm
+	    DeletedAt: any;
+	    page_id: string;
+	    title: string;
+	    html_content: string;
+	    blocks: number[];
+	    hash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PageRevision(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.CreatedAt = this.convertValues(source["CreatedAt"], null);
+	        this.UpdatedAt = this.convertValues(source["UpdatedAt"], null);
+	        this.DeletedAt = this.convertValues(source["DeletedAt"], null);
+	        this.page_id = source["page_id"];
+	        this.title = source["title"];
+	        this.html_content = source["html_content"];
+	        this.blocks = source["blocks"];
+	        this.hash = source["hash"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PageRevisionDiff {
+	    from?: PageRevision;
+	    to?: PageRevision;
+	    title: utils.DiffPart[];
+	    content: utils.DiffPart[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PageRevisionDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = this.convertValues(source["from"], PageRevision);
+	        this.to = this.convertValues(source["to"], PageRevision);
+	        this.title = this.convertValues(source["title"], utils.DiffPart);
+	        this.content = this.convertValues(source["content"], utils.DiffPart);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PageSearchResult {
+	    id: string;
+	    title: string;
+	    snippet: string;
+	    path: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PageSearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.snippet = source["snippet"];
+	        this.path = source["path"];
+	    }
+	}
+	export class ReadProgress {
+	    ID: string;
+	    // Go type: time
+	    CreatedAt: any;
+	    // Go type: time
+	    UpdatedAt: any;
+	    // Go type: gorm
+	    DeletedAt: any;
+	    page_id: string;
+	    device_id: string;
+	    progress: number;
+	    total: number;
+	    // Go type: time
+	    read_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReadProgress(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.CreatedAt = this.convertValues(source["CreatedAt"], null);
+	        this.UpdatedAt = this.convertValues(source["UpdatedAt"], null);
+	        this.DeletedAt = this.convertValues(source["DeletedAt"], null);
+	        this.page_id = source["page_id"];
+	        this.device_id = source["device_id"];
+	        this.progress = source["progress"];
+	        this.total = source["total"];
+	        this.read_at = this.convertValues(source["read_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReadingSession {
+	    ID: string;
+	    // Go type: time
+	    CreatedAt: any;
+	    // Go type: time
+	    UpdatedAt: any;
+	    // Go type: gorm
+	    DeletedAt: any;
+	    page_id: string;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    ended_at?: any;
+	    duration: number;
+	    language: string;
+	    provider: string;
+	    words_covered: number;
+	    words_per_minute: number;
+	    error_count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ReadingSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.CreatedAt = this.convertValues(source["CreatedAt"], null);
+	        this.UpdatedAt = this.convertValues(source["UpdatedAt"], null);
+	        this.DeletedAt = this.convertValues(source["DeletedAt"], null);
+	        this.page_id = source["page_id"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.ended_at = this.convertValues(source["ended_at"], null);
+	        this.duration = source["duration"];
+	        this.language = source["language"];
+	        this.provider = source["provider"];
+	        this.words_covered = source["words_covered"];
+	        this.words_per_minute = source["words_per_minute"];
+	        this.error_count = source["error_count"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ReadingStats {
+	    page_id: string;
+	    times_rehearsed: number;
+	    total_duration: number;
+	    average_duration: number;
+	    average_words_per_minute: number;
+	    average_error_count: number;
+	    // Go type: time
+	    last_read_at?: any;
+	    trend: ReadingSession[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ReadingStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.page_id = source["page_id"];
+	        this.times_rehearsed = source["times_rehearsed"];
+	        this.total_duration = source["total_duration"];
+	        this.average_duration = source["average_duration"];
+	        this.average_words_per_minute = source["average_words_per_minute"];
+	        this.average_error_count = source["average_error_count"];
+	        this.last_read_at = this.convertValues(source["last_read_at"], null);
+	        this.trend = this.convertValues(source["trend"], ReadingSession);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RecordingSession {
+	    ID: number;
+	    // Go type: time
+	    CreatedAt: any;
+	    // Go type: time
+	    UpdatedAt: any;
+	    // Go type: gorm
+	    DeletedAt: any;
+	    PageID: string;
+	    DeviceName: string;
+	    Language: string;
+	    // Go type: time
+	    StartedAt: any;
+	    // Go type: time
+	    EndedAt?: any;
+	    Duration: number;
+	    FileName: string;
+	    FileSize: number;
+	    SampleRate: number;
+	    Channels: number;
+	    Coverage: number[];
+	    Transcript: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RecordingSession(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ID = source["ID"];
+	        this.CreatedAt = this.convertValues(source["CreatedAt"], null);
+	        this.UpdatedAt = this.convertValues(source["UpdatedAt"], null);
+	        this.DeletedAt = this.convertValues(source["DeletedAt"], null);
+	        this.PageID = source["PageID"];
+	        this.DeviceName = source["DeviceName"];
+	        this.Language = source["Language"];
+	        this.StartedAt = this.convertValues(source["StartedAt"], null);
+	        this.EndedAt = this.convertValues(source["EndedAt"], null);
+	        this.Duration = source["Duration"];
+	        this.FileName = source["FileName"];
+	        this.FileSize = source["FileSize"];
+	        this.SampleRate = source["SampleRate"];
+	        this.Channels = source["Channels"];
+	        this.Coverage = source["Coverage"];
+	        this.Transcript = source["Transcript"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -322,6 +950,25 @@ export namespace structs {
 
 }
 
+export namespace utils {
+	
+	export class DiffPart {
+	    type: string;
+	    text: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiffPart(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.text = source["text"];
+	    }
+	}
+
+}
+
 export namespace whisper {
 	
 	export class WhisperModel {
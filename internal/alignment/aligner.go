@@ -0,0 +1,271 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package alignment
+
+import (
+	"math"
+	"myscript/internal/cues"
+	"sort"
+	"sync"
+	"unicode/utf16"
+)
+
+const (
+	// Smith-Waterman scores
+	MATCH_SCORE        = 2.0
+	SIMILAR_SCORE      = 1.0
+	MISMATCH_PENALTY   = -1.0
+	GAP_PENALTY        = -1.0
+	SIMILAR_WORD_RATIO = 0.75 // Levenshtein similarity of two words considered similar
+
+	// Band of script words searched around the reading position
+	WINDOW_BEHIND = 20  // Words before the position, when a sentence is read again
+	WINDOW_AHEAD  = 150 // Words after the position
+
+	// Score lost per word between the start of a match and the reading position,
+	// so the closest occurrence of a repeated phrase wins
+	DISTANCE_PENALTY = 0.02
+
+	MIN_CONFIDENCE = 0.4
+	// Matches outside of the band move the reading position far away, they must be reliable
+	MIN_JUMP_CONFIDENCE = 0.7
+)
+
+type Match struct {
+	StartWord  int     `json:"start_word"` // First matched script word
+	EndWord    int     `json:"end_word"`   // Excluded, it is the new reading position
+	Start      int     `json:"start"`      // UTF-16 offset of the first matched word
+	End        int     `json:"end"`        // UTF-16 offset of the end of the last matched word
+	Confidence float64 `json:"confidence"` // Between 0 and 1
+	Transcript string  `json:"transcript"`
+	Script     string  `json:"script"` // Matched script text, without cues
+}
+
+// Aligner follows the reading position in a script from the transcripts
+type Aligner struct {
+	language  string
+	text      []uint16 // UTF-16 units of the script
+	words     []Word
+	sentences []Sentence
+	cues      []cues.Cue
+	position  int // Index of the next expected word
+	mu        sync.Mutex
+}
+
+// NewAligner tokenizes the script text once. Transcripts are normalized with the same language.
+// Cue directives are not read, they are kept with the index of the word following them.
+func NewAligner(text, language string) *Aligner {
+	scriptCues := cues.Parse(text)
+
+	var words []Word
+	next := 0
+	for _, word := range Tokenize(text, language) {
+		for next < len(scriptCues) && scriptCues[next].End <= word.Start {
+			scriptCues[next].Word = len(words)
+			next++
+		}
+
+		if next < len(scriptCues) && word.Start >= scriptCues[next].Start {
+			continue // Inside the cue
+		}
+		words = append(words, word)
+	}
+	for ; next < len(scriptCues); next++ {
+		scriptCues[next].Word = len(words)
+	}
+
+	return &Aligner{
+		language:  language,
+		text:      utf16.Encode([]rune(text)),
+		words:     words,
+		sentences: splitSentences(text, words, language),
+		cues:      scriptCues,
+	}
+}
+
+func (a *Aligner) Words() []Word {
+	return a.words
+}
+
+func (a *Aligner) Cues() []cues.Cue {
+	return a.cues
+}
+
+// CuesPassed returns the cues passed when the reading position moves forward from one word to another.
+// Cues before the first word are passed as soon as the reading starts.
+func (a *Aligner) CuesPassed(from, to int) []cues.Cue {
+	var passed []cues.Cue
+	for _, cue := range a.cues {
+		if (cue.Word > from || (cue.Word == 0 && from == 0)) && cue.Word <= to {
+			passed = append(passed, cue)
+		}
+	}
+	return passed
+}
+
+func (a *Aligner) Position() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.position
+}
+
+func (a *Aligner) SetPosition(wordIndex int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.position = max(0, min(wordIndex, len(a.words)))
+}
+
+// SetOffset moves the reading position to the first word ending after the UTF-16 offset
+func (a *Aligner) SetOffset(offset int) {
+	a.SetPosition(sort.Search(len(a.words), func(i int) bool {
+		return a.words[i].End > offset
+	}))
+}
+
+// Align matches the transcript with the script around the reading position, and
+// moves the position after the match. It returns nil when no reliable match is found.
+func (a *Aligner) Align(transcript string) *Match {
+	query := Tokenize(CleanTranscript(transcript), a.language)
+	if len(query) == 0 || len(a.words) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	from := max(0, a.position-WINDOW_BEHIND)
+	to := min(len(a.words), a.position+max(WINDOW_AHEAD, 3*len(query)))
+
+	match := a.align(query, from, to, a.position)
+
+	if match == nil || match.Confidence < MIN_CONFIDENCE {
+		// The reader may have skipped a part of the script
+		match = a.align(query, 0, len(a.words), a.position)
+		if match == nil || match.Confidence < MIN_JUMP_CONFIDENCE {
+			return nil
+		}
+	}
+
+	match.Transcript = transcript
+	match.Script = cues.Strip(string(utf16.Decode(a.text[match.Start:match.End])))
+	a.position = match.EndWord
+
+	return match
+}
+
+// alignNear returns the best local alignment starting around the position, whatever its confidence.
+// It does not move the reading position.
+func (a *Aligner) alignNear(query []Word, position int) *Match {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	from := max(0, position-len(query))
+	to := min(len(a.words), position+2*len(query))
+
+	return a.align(query, from, to, position)
+}
+
+// align runs a Smith-Waterman local alignment of the query against the script words [from, to).
+// Matches starting close to the position are preferred.
+func (a *Aligner) align(query []Word, from, to, position int) *Match {
+	rows, cols := len(query)+1, to-from+1
+	if cols <= 1 {
+		return nil
+	}
+
+	scores := make([]float64, rows*cols)
+	at := func(i, j int) int { return i*cols + j }
+
+	bestScore, bestRank := 0.0, math.Inf(-1)
+	bestI, bestJ := 0, 0
+
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			score := math.Max(0, scores[at(i-1, j-1)]+wordScore(query[i-1], a.words[from+j-1]))
+			score = math.Max(score, scores[at(i-1, j)]+GAP_PENALTY)
+			score = math.Max(score, scores[at(i, j-1)]+GAP_PENALTY)
+			scores[at(i, j)] = score
+
+			if score == 0 {
+				continue
+			}
+
+			// The match ending here started about i words earlier
+			distance := math.Abs(float64(from + j - i - position))
+			if rank := score - DISTANCE_PENALTY*distance; rank > bestRank {
+				bestScore, bestRank = score, rank
+				bestI, bestJ = i, j
+			}
+		}
+	}
+
+	if bestScore == 0 {
+		return nil
+	}
+
+	// Trace back to the start of the local alignment
+	i, j := bestI, bestJ
+	for i > 0 && j > 0 && scores[at(i, j)] > 0 {
+		switch scores[at(i, j)] {
+		case scores[at(i-1, j-1)] + wordScore(query[i-1], a.words[from+j-1]):
+			i, j = i-1, j-1
+		case scores[at(i-1, j)] + GAP_PENALTY:
+			i--
+		default:
+			j--
+		}
+	}
+
+	startWord, endWord := from+j, from+bestJ
+
+	return &Match{
+		StartWord:  startWord,
+		EndWord:    endWord,
+		Start:      a.words[startWord].Start,
+		End:        a.words[endWord-1].End,
+		Confidence: math.Min(1, bestScore/(MATCH_SCORE*float64(len(query)))),
+	}
+}
+
+func wordScore(a, b Word) float64 {
+	switch {
+	case a.Normalized == b.Normalized:
+		return MATCH_SCORE
+	case similarity(a.Normalized, b.Normalized) >= SIMILAR_WORD_RATIO:
+		return SIMILAR_SCORE
+	default:
+		return MISMATCH_PENALTY
+	}
+}
+
+// similarity returns the Levenshtein similarity of two words, between 0 and 1
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(longest)
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package alignment
+
+import (
+	"myscript/internal/normalize"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+)
+
+const (
+	SENTENCE_READ        = "read"
+	SENTENCE_PARAPHRASED = "paraphrased"
+	SENTENCE_SKIPPED     = "skipped"
+	SENTENCE_INSERTED    = "inserted"
+
+	// Share of the sentence words read to consider the sentence read
+	READ_SIMILARITY = 0.85
+	// Below, a sentence is paraphrased only if enough words were spoken in its place
+	MIN_PARAPHRASE_SIMILARITY = 0.3
+	MIN_PARAPHRASE_SPOKEN     = 0.5
+
+	// Transcripts not aligned during the session are compared again with the script
+	// at the reading position, paraphrases have a low confidence
+	MIN_PARAPHRASE_CONFIDENCE = 0.15
+
+	// Shorter insertions are usually transcription noise or fillers
+	MIN_INSERTED_WORDS = 4
+)
+
+type SentenceCoverage struct {
+	Status     string  `json:"status"`
+	Text       string  `json:"text"`       // Script text, or the inserted text
+	Spoken     string  `json:"spoken"`     // Transcript words aligned with the sentence
+	Similarity float64 `json:"similarity"` // Between 0 and 1
+	Start      int     `json:"start"`      // UTF-16 offsets in the script, an insertion is empty
+	End        int     `json:"end"`
+}
+
+type CoverageReport struct {
+	Sentences    []SentenceCoverage `json:"sentences"`
+	Read         int                `json:"read"`
+	Paraphrased  int                `json:"paraphrased"`
+	Skipped      int                `json:"skipped"`
+	Inserted     int                `json:"inserted"`
+	WordsCovered int                `json:"words_covered"` // Script words aligned with a transcript word
+}
+
+// spokenWord is a transcript word, the words spelled out from the same source are reported once
+type spokenWord struct {
+	word       Word
+	transcript int // Index of the transcript in the session
+}
+
+type insertion struct {
+	after int // Script word the text was spoken after, -1 before the first word
+	words []string
+}
+
+// Coverage collects the aligned transcripts of a session, to report which
+// sentences of the script were read, paraphrased or skipped
+type Coverage struct {
+	aligner     *Aligner
+	similarity  []float64     // Best similarity of the transcript word aligned with each script word
+	spoken      []*spokenWord // Last transcript word aligned with each script word
+	insertions  []insertion
+	transcripts int
+	mu          sync.Mutex
+}
+
+func NewCoverage(aligner *Aligner) *Coverage {
+	return &Coverage{
+		aligner:    aligner,
+		similarity: make([]float64, len(aligner.words)),
+		spoken:     make([]*spokenWord, len(aligner.words)),
+	}
+}
+
+func (c *Coverage) Aligner() *Aligner {
+	return c.aligner
+}
+
+// Add records a transcript with its match. Without a match, the transcript is compared with
+// the script at the given word position, and is considered inserted if it does not match.
+func (c *Coverage) Add(transcript string, match *Match, position int) {
+	query := Tokenize(CleanTranscript(transcript), c.aligner.language)
+	if len(query) == 0 {
+		return
+	}
+
+	if match == nil {
+		if near := c.aligner.alignNear(query, position); near != nil && near.Confidence >= MIN_PARAPHRASE_CONFIDENCE {
+			// Only a few words of a paraphrase match, it covers about as many script words
+			// as spoken, up to the end of the sentence
+			end := c.aligner.sentenceAt(near.EndWord - 1).EndWord
+			near.EndWord = max(near.EndWord, min(end, near.StartWord+len(query)))
+			match = near
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.transcripts++
+
+	if match == nil {
+		c.insert(position-1, query)
+		return
+	}
+
+	c.addAligned(query, match.StartWord, match.EndWord)
+}
+
+// addAligned runs a global alignment of the transcript with the matched script words.
+// Must be called with the lock held.
+func (c *Coverage) addAligned(query []Word, from, to int) {
+	words := c.aligner.words[from:to]
+	rows, cols := len(query)+1, len(words)+1
+
+	scores := make([]float64, rows*cols)
+	at := func(i, j int) int { return i*cols + j }
+
+	for i := 1; i < rows; i++ {
+		scores[at(i, 0)] = float64(i) * GAP_PENALTY
+	}
+	for j := 1; j < cols; j++ {
+		scores[at(0, j)] = float64(j) * GAP_PENALTY
+	}
+
+	for i := 1; i < rows; i++ {
+		for j := 1; j < cols; j++ {
+			scores[at(i, j)] = max(
+				scores[at(i-1, j-1)]+wordScore(query[i-1], words[j-1]),
+				scores[at(i-1, j)]+GAP_PENALTY,
+				scores[at(i, j-1)]+GAP_PENALTY,
+			)
+		}
+	}
+
+	// Trace back from the end, the inserted words are collected in reverse order
+	var inserted []Word
+	flushInserted := func(after int) {
+		if len(inserted) == 0 {
+			return
+		}
+		for l, r := 0, len(inserted)-1; l < r; l, r = l+1, r-1 {
+			inserted[l], inserted[r] = inserted[r], inserted[l]
+		}
+		c.insert(after, inserted)
+		inserted = nil
+	}
+
+	i, j := rows-1, cols-1
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && scores[at(i, j)] == scores[at(i-1, j-1)]+wordScore(query[i-1], words[j-1]):
+			flushInserted(from + j - 1)
+
+			word := from + j - 1
+			c.spoken[word] = &spokenWord{word: query[i-1], transcript: c.transcripts}
+			if query[i-1].Normalized == words[j-1].Normalized {
+				c.similarity[word] = 1
+			} else if score := similarity(query[i-1].Normalized, words[j-1].Normalized); score >= SIMILAR_WORD_RATIO {
+				c.similarity[word] = max(c.similarity[word], score)
+			}
+			i, j = i-1, j-1
+		case i > 0 && (j == 0 || scores[at(i, j)] == scores[at(i-1, j)]+GAP_PENALTY):
+			inserted = append(inserted, query[i-1])
+			i--
+		default:
+			flushInserted(from + j - 1)
+			j--
+		}
+	}
+	flushInserted(from - 1)
+}
+
+// insert must be called with the lock held
+func (c *Coverage) insert(after int, words []Word) {
+	var text []string
+	for i, word := range words {
+		if i > 0 && word.Start == words[i-1].Start {
+			continue
+		}
+		text = append(text, word.Text)
+	}
+
+	c.insertions = append(c.insertions, insertion{after: after, words: text})
+}
+
+// insertionOffset returns the UTF-16 offset of a text spoken after a script word,
+// 0 when the script has no words
+func (c *Coverage) insertionOffset(after int) int {
+	if len(c.aligner.words) == 0 {
+		return 0
+	}
+	return c.aligner.words[max(0, after)].End
+}
+
+// Report compares the collected transcripts with the script, sentence by sentence
+func (c *Coverage) Report() *CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &CoverageReport{Sentences: []SentenceCoverage{}}
+	sentences := c.aligner.Sentences()
+
+	addInsertions := func(from, to int) {
+		for _, insertion := range c.insertions {
+			if insertion.after < from || insertion.after >= to || len(insertion.words) < MIN_INSERTED_WORDS {
+				continue
+			}
+
+			report.Inserted++
+			report.Sentences = append(report.Sentences, SentenceCoverage{
+				Status: SENTENCE_INSERTED,
+				Text:   strings.Join(insertion.words, " "),
+				Spoken: strings.Join(insertion.words, " "),
+				Start:  c.insertionOffset(insertion.after),
+				End:    c.insertionOffset(insertion.after),
+			})
+		}
+	}
+
+	addInsertions(-1, 0)
+
+	for _, sentence := range sentences {
+		var read float64
+		var spoken []string
+		var last *spokenWord
+
+		for word := sentence.StartWord; word < sentence.EndWord; word++ {
+			read += c.similarity[word]
+
+			current := c.spoken[word]
+			if current == nil {
+				continue
+			}
+			report.WordsCovered++
+			if last == nil || current.transcript != last.transcript || current.word.Start != last.word.Start {
+				spoken = append(spoken, current.word.Text)
+			}
+			last = current
+		}
+
+		count := float64(sentence.EndWord - sentence.StartWord)
+		coverage := SentenceCoverage{
+			Text:       sentence.Text,
+			Spoken:     strings.Join(spoken, " "),
+			Similarity: read / count,
+			Start:      sentence.Start,
+			End:        sentence.End,
+		}
+
+		switch {
+		case coverage.Similarity >= READ_SIMILARITY:
+			coverage.Status = SENTENCE_READ
+			report.Read++
+		case coverage.Similarity >= MIN_PARAPHRASE_SIMILARITY || float64(len(spoken)) >= MIN_PARAPHRASE_SPOKEN*count:
+			coverage.Status = SENTENCE_PARAPHRASED
+			report.Paraphrased++
+		default:
+			coverage.Status = SENTENCE_SKIPPED
+			report.Skipped++
+		}
+
+		report.Sentences = append(report.Sentences, coverage)
+		addInsertions(sentence.StartWord, sentence.EndWord)
+	}
+
+	return report
+}
+
+// --- Sentences ---
+
+type Sentence struct {
+	Text      string
+	StartWord int
+	EndWord   int // Excluded
+	Start     int // UTF-16 offsets in the text
+	End       int
+}
+
+// Sentences returns the script split on sentence punctuation and line breaks
+func (a *Aligner) Sentences() []Sentence {
+	return a.sentences
+}
+
+// sentenceAt returns the sentence containing the word
+func (a *Aligner) sentenceAt(word int) Sentence {
+	i := sort.Search(len(a.sentences), func(i int) bool {
+		return a.sentences[i].EndWord > word
+	})
+
+	return a.sentences[min(i, len(a.sentences)-1)]
+}
+
+// splitSentences splits the text after the words followed by sentence punctuation or a line break.
+// The dot of an abbreviation, as in "Mr. Brown", does not end a sentence.
+func splitSentences(text string, words []Word, language string) []Sentence {
+	units := utf16.Encode([]rune(text))
+
+	var sentences []Sentence
+	start := 0
+
+	for i, word := range words {
+		gapEnd := len(units)
+		if i+1 < len(words) {
+			gapEnd = words[i+1].Start
+		}
+
+		// Words spelled out from the same source share their offsets
+		gap := string(utf16.Decode(units[word.End:max(word.End, gapEnd)]))
+		if i+1 < len(words) && !strings.ContainsAny(gap, ".!?…\n") {
+			continue
+		}
+		if i+1 < len(words) && strings.TrimRightFunc(gap, unicode.IsSpace) == "." && !strings.Contains(gap, "\n") &&
+			normalize.IsAbbreviation(word.Text, language) {
+			continue
+		}
+
+		sentences = append(sentences, Sentence{
+			Text:      string(utf16.Decode(units[words[start].Start:word.End])) + trailingPunctuation(gap),
+			StartWord: start,
+			EndWord:   i + 1,
+			Start:     words[start].Start,
+			End:       word.End,
+		})
+		start = i + 1
+	}
+
+	return sentences
+}
+
+// trailingPunctuation returns the punctuation closing a sentence, up to the next space
+func trailingPunctuation(gap string) string {
+	if i := strings.IndexFunc(gap, unicode.IsSpace); i >= 0 {
+		return gap[:i]
+	}
+	return gap
+}
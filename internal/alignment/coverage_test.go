@@ -0,0 +1,133 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package alignment
+
+import "testing"
+
+func TestSplitSentences(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		language string
+		want     []string
+	}{
+		{
+			name:     "abbreviation",
+			text:     "Mr. Brown went home. He slept.",
+			language: "en",
+			want:     []string{"Mr. Brown went home.", "He slept."},
+		},
+		{
+			name:     "abbreviation with inner dots",
+			text:     "Bring fruit, e.g. apples! Then rest?",
+			language: "en",
+			want:     []string{"Bring fruit, e.g. apples!", "Then rest?"},
+		},
+		{
+			name:     "dotted only abbreviation",
+			text:     "M. Dupont est là. Il dort.",
+			language: "fr",
+			want:     []string{"M. Dupont est là.", "Il dort."},
+		},
+		{
+			name:     "abbreviation before a line break",
+			text:     "Call the Dr.\nShe knows.",
+			language: "en",
+			want:     []string{"Call the Dr.", "She knows."},
+		},
+		{
+			name:     "abbreviation ending the text",
+			text:     "Ask Mr.",
+			language: "en",
+			want:     []string{"Ask Mr."},
+		},
+		{
+			name:     "unsupported language",
+			text:     "Mr. Brown.",
+			language: "ja",
+			want:     []string{"Mr.", "Brown."},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sentences := NewAligner(c.text, c.language).Sentences()
+
+			if len(sentences) != len(c.want) {
+				t.Fatalf("got %d sentences, want %d: %+v", len(sentences), len(c.want), sentences)
+			}
+			for i, want := range c.want {
+				if sentences[i].Text != want {
+					t.Errorf("sentence %d = %q, want %q", i, sentences[i].Text, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitSentencesWords(t *testing.T) {
+	sentences := NewAligner("Mr. Brown went home. He slept.", "en").Sentences()
+	if len(sentences) != 2 {
+		t.Fatalf("got %d sentences, want 2", len(sentences))
+	}
+
+	// mister brown went home | he slept
+	first, second := sentences[0], sentences[1]
+	if first.StartWord != 0 || first.EndWord != 4 || second.StartWord != 4 || second.EndWord != 6 {
+		t.Errorf("word ranges = [%d, %d) [%d, %d), want [0, 4) [4, 6)",
+			first.StartWord, first.EndWord, second.StartWord, second.EndWord)
+	}
+	if first.Start != 0 || first.End != 19 || second.Start != 21 || second.End != 29 {
+		t.Errorf("offsets = [%d, %d) [%d, %d), want [0, 19) [21, 29)",
+			first.Start, first.End, second.Start, second.End)
+	}
+}
+
+func TestCoverageReportEmptyScript(t *testing.T) {
+	for _, text := range []string{"", "[SLIDE 2] [PAUSE]"} {
+		aligner := NewAligner(text, "en")
+		coverage := NewCoverage(aligner)
+
+		transcript := "this was not written in the script"
+		coverage.Add(transcript, aligner.Align(transcript), aligner.Position())
+
+		report := coverage.Report()
+		if report.Inserted != 1 || len(report.Sentences) != 1 {
+			t.Fatalf("script %q: got %+v, want a single insertion", text, report)
+		}
+
+		inserted := report.Sentences[0]
+		if inserted.Status != SENTENCE_INSERTED || inserted.Start != 0 || inserted.End != 0 {
+			t.Errorf("script %q: insertion = %+v, want an insertion at offset 0", text, inserted)
+		}
+	}
+}
+
+func TestAlignerCues(t *testing.T) {
+	text := "Good evening.\n[SLIDE 2] Welcome home. [PAUSE]"
+	aligner := NewAligner(text, "en")
+
+	// The cues keep their offsets in the text given to the aligner, and the index of the next word
+	want := []struct {
+		text       string
+		word       int
+		start, end int
+	}{
+		{"[SLIDE 2]", 2, 14, 23},
+		{"[PAUSE]", 4, 38, 45},
+	}
+
+	got := aligner.Cues()
+	if len(got) != len(want) {
+		t.Fatalf("got %d cues, want %d: %+v", len(got), len(want), got)
+	}
+	for i, cue := range got {
+		if cue.Text != want[i].text || cue.Word != want[i].word || cue.Start != want[i].start || cue.End != want[i].end {
+			t.Errorf("cue %d = %+v, want %+v", i, cue, want[i])
+		}
+	}
+	if words := aligner.Words(); len(words) != 4 {
+		t.Errorf("got %d words, want the cues left out of the 4 words", len(words))
+	}
+}
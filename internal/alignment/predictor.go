@@ -0,0 +1,188 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package alignment
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// Speaking time used to estimate the rate
+	RATE_WINDOW = 60 * time.Second
+	// Minimum speaking time between the observations before a rate is estimated
+	MIN_RATE_SPAN = 4 * time.Second
+
+	MIN_WORDS_PER_MINUTE = 60
+	MAX_WORDS_PER_MINUTE = 300
+
+	// An observation this far from the expected position is a jump in the script,
+	// the previous observations no longer describe the reading pace
+	MAX_RATE_DEVIATION = 30 // Words
+
+	// The prediction never runs further ahead of the last aligned word
+	MAX_PREDICTION_AHEAD = 40 // Words
+)
+
+type Prediction struct {
+	Word           int     `json:"word"`  // Index of the word predicted to be read
+	Start          int     `json:"start"` // UTF-16 offsets of the word
+	End            int     `json:"end"`
+	WordsPerMinute float64 `json:"words_per_minute"`
+}
+
+type rateSample struct {
+	speechTime time.Duration
+	word       int
+}
+
+// Predictor estimates the speaking rate from the aligned positions, and predicts the
+// word being read between two alignments. Time is measured as speaking time, so the
+// prediction holds while the reader is silent.
+type Predictor struct {
+	totalWords int
+
+	speechTime time.Duration // Cumulative speaking time
+	speaking   bool
+	lastTick   time.Time
+
+	samples   []rateSample
+	predicted float64
+	mu        sync.Mutex
+}
+
+func NewPredictor(totalWords, position int) *Predictor {
+	return &Predictor{
+		totalWords: totalWords,
+		predicted:  float64(position),
+		lastTick:   time.Now(),
+	}
+}
+
+// SetSpeaking updates the voice activity of the reader
+func (p *Predictor) SetSpeaking(speaking bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advance(time.Now())
+	p.speaking = speaking
+}
+
+// Mark returns the current speaking time. It is taken when an audio chunk ends,
+// and given back to Observe with the aligned position of its transcript.
+func (p *Predictor) Mark() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advance(time.Now())
+	return p.speechTime
+}
+
+// Observe corrects the prediction with the word aligned at the end of a chunk
+func (p *Predictor) Observe(mark time.Duration, word int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advance(time.Now())
+
+	if len(p.samples) > 0 {
+		last := p.samples[len(p.samples)-1]
+		rate := p.wordsPerSecond()
+		expected := float64(last.word) + rate*(mark-last.speechTime).Seconds()
+
+		if word < last.word || (rate > 0 && math.Abs(float64(word)-expected) > MAX_RATE_DEVIATION) {
+			p.samples = nil
+		}
+	}
+
+	p.samples = append(p.samples, rateSample{speechTime: mark, word: word})
+
+	// Forget the observations out of the window
+	for len(p.samples) > 2 && mark-p.samples[0].speechTime > RATE_WINDOW {
+		p.samples = p.samples[1:]
+	}
+
+	// The words spoken since the end of the chunk are still being transcribed
+	p.predicted = float64(word) + p.wordsPerSecond()*(p.speechTime-mark).Seconds()
+	p.clamp(word)
+}
+
+// Predict returns the predicted index of the word being read
+func (p *Predictor) Predict() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.advance(time.Now())
+
+	return int(p.predicted)
+}
+
+// WordsPerMinute returns the estimated speaking rate, 0 while unknown
+func (p *Predictor) WordsPerMinute() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.wordsPerSecond() * 60
+}
+
+// advance moves the speaking time and the prediction to now.
+// Must be called with the lock held.
+func (p *Predictor) advance(now time.Time) {
+	elapsed := now.Sub(p.lastTick)
+	p.lastTick = now
+
+	if !p.speaking || elapsed <= 0 {
+		return
+	}
+
+	p.speechTime += elapsed
+	p.predicted += p.wordsPerSecond() * elapsed.Seconds()
+
+	if len(p.samples) > 0 {
+		p.clamp(p.samples[len(p.samples)-1].word)
+	}
+}
+
+func (p *Predictor) clamp(lastWord int) {
+	p.predicted = max(p.predicted, float64(lastWord))
+	p.predicted = min(p.predicted, float64(lastWord+MAX_PREDICTION_AHEAD), float64(p.totalWords))
+}
+
+// wordsPerSecond is the least squares slope of the aligned words over the speaking time.
+// Must be called with the lock held.
+func (p *Predictor) wordsPerSecond() float64 {
+	if len(p.samples) < 2 {
+		return 0
+	}
+
+	first, last := p.samples[0], p.samples[len(p.samples)-1]
+	if last.speechTime-first.speechTime < MIN_RATE_SPAN {
+		return 0
+	}
+
+	var meanT, meanW float64
+	for _, sample := range p.samples {
+		meanT += sample.speechTime.Seconds()
+		meanW += float64(sample.word)
+	}
+	meanT /= float64(len(p.samples))
+	meanW /= float64(len(p.samples))
+
+	var covariance, variance float64
+	for _, sample := range p.samples {
+		dt := sample.speechTime.Seconds() - meanT
+		covariance += dt * (float64(sample.word) - meanW)
+		variance += dt * dt
+	}
+
+	if variance == 0 {
+		return 0
+	}
+
+	rate := covariance / variance * 60
+	rate = max(MIN_WORDS_PER_MINUTE, min(MAX_WORDS_PER_MINUTE, rate))
+
+	return rate / 60
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package alignment
+
+import (
+	"myscript/internal/normalize"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Transcribers annotate non-speech parts between brackets, e.g. [BLANK_AUDIO]
+var annotationRegex = regexp.MustCompile(`\[.*?\]|\(.*?\)`)
+
+type Word struct {
+	Text       string `json:"text"`       // As written in the text, shared by the words of an expansion
+	Normalized string `json:"normalized"` // Compared form
+	Start      int    `json:"start"`      // UTF-16 offset in the text, as counted by the DOM
+	End        int    `json:"end"`
+}
+
+// Tokenize splits the text into normalized words for the language. Numbers, amounts, dates
+// and abbreviations are spelled out, so "$5" gives the words "five" and "dollars",
+// both with the offsets of "$5".
+func Tokenize(text, language string) []Word {
+	tokens := normalize.Tokenize(text, language)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// UTF-16 offset of each byte offset
+	offsets := make([]int, len(text)+1)
+	offset := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		for j := i; j < i+size; j++ {
+			offsets[j] = offset
+		}
+		offset += utf16.RuneLen(r)
+		i += size
+	}
+	offsets[len(text)] = offset
+
+	words := make([]Word, len(tokens))
+	for i, token := range tokens {
+		words[i] = Word{
+			Text:       token.Source,
+			Normalized: token.Text,
+			Start:      offsets[token.Start],
+			End:        offsets[token.End],
+		}
+	}
+
+	return words
+}
+
+// CleanTranscript removes the annotations added by the transcribers
+func CleanTranscript(transcript string) string {
+	return strings.TrimSpace(annotationRegex.ReplaceAllString(transcript, " "))
+}
@@ -0,0 +1,322 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+// Package blocks builds the JSON document of the page editor (Tiptap) from HTML,
+// so imported pages open in the editor as they were written.
+package blocks
+
+import (
+	"encoding/json"
+	"myscript/internal/utils"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"gorm.io/datatypes"
+)
+
+type Mark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+type Node struct {
+	Type    string         `json:"type"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []*Node        `json:"content,omitempty"`
+	Marks   []Mark         `json:"marks,omitempty"`
+	Text    string         `json:"text,omitempty"`
+}
+
+// FromHTML returns the editor document of an HTML content
+func FromHTML(content string) datatypes.JSON {
+	document := &Node{Type: "doc"}
+
+	document.Content = convertBlocks(utils.ParseHTMLFragment(content))
+
+	// The editor expects at least one block
+	if len(document.Content) == 0 {
+		document.Content = []*Node{{Type: "paragraph"}}
+	}
+
+	data, _ := json.Marshal(document)
+
+	return datatypes.JSON(data)
+}
+
+// convertBlocks converts the children of a block element.
+// Inline content found between blocks is wrapped in paragraphs.
+func convertBlocks(parent *html.Node) []*Node {
+	var blocks []*Node
+	var inline []*Node
+
+	flush := func() {
+		if paragraph := newTextBlock("paragraph", inline); len(paragraph.Content) > 0 {
+			blocks = append(blocks, paragraph)
+		}
+		inline = nil
+	}
+
+	for child := parent.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && isBlock(child) {
+			flush()
+			blocks = append(blocks, convertBlock(child)...)
+			continue
+		}
+
+		inline = append(inline, convertInline(child, nil)...)
+	}
+	flush()
+
+	return blocks
+}
+
+func isBlock(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Li, atom.Blockquote, atom.Pre, atom.Hr, atom.Img,
+		atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main,
+		atom.Table, atom.Thead, atom.Tbody, atom.Tfoot, atom.Tr, atom.Td, atom.Th,
+		atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd:
+		return true
+	}
+	return false
+}
+
+func convertBlock(n *html.Node) []*Node {
+	switch n.DataAtom {
+	case atom.P, atom.Dt, atom.Dd, atom.Td, atom.Th, atom.Figcaption:
+		if image := onlyImage(n); image != nil {
+			return []*Node{newImage(image)}
+		}
+
+		// Empty paragraphs are kept, they are blank lines of the script
+		paragraph := newTextBlock("paragraph", convertChildren(n, nil))
+		if n.DataAtom != atom.P && len(paragraph.Content) == 0 {
+			return nil
+		}
+		return []*Node{paragraph}
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		heading := newTextBlock("heading", convertChildren(n, nil))
+		heading.Attrs = map[string]any{"level": int(n.Data[1] - '0')}
+		return []*Node{heading}
+
+	case atom.Ul, atom.Ol:
+		list := &Node{Type: "bulletList"}
+		if n.DataAtom == atom.Ol {
+			list.Type = "orderedList"
+			list.Attrs = map[string]any{"start": 1}
+			if start, err := strconv.Atoi(utils.HTMLAttribute(n, "start")); err == nil {
+				list.Attrs["start"] = start
+			}
+		}
+
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && child.DataAtom == atom.Li {
+				list.Content = append(list.Content, convertListItem(child))
+			}
+		}
+		if len(list.Content) == 0 {
+			return nil
+		}
+		return []*Node{list}
+
+	case atom.Li:
+		// Outside of a list
+		return []*Node{{Type: "bulletList", Content: []*Node{convertListItem(n)}}}
+
+	case atom.Blockquote:
+		content := convertBlocks(n)
+		if len(content) == 0 {
+			content = []*Node{{Type: "paragraph"}}
+		}
+		return []*Node{{Type: "blockquote", Content: content}}
+
+	case atom.Pre:
+		code := &Node{Type: "codeBlock", Attrs: map[string]any{"language": nil}}
+		if language := utils.HTMLCodeLanguage(n); language != "" {
+			code.Attrs["language"] = language
+		}
+		if text := strings.TrimSuffix(utils.HTMLTextContent(n), "\n"); text != "" {
+			code.Content = []*Node{{Type: "text", Text: text}}
+		}
+		return []*Node{code}
+
+	case atom.Hr:
+		return []*Node{{Type: "horizontalRule"}}
+
+	case atom.Img:
+		return []*Node{newImage(n)}
+
+	default:
+		// Containers
+		return convertBlocks(n)
+	}
+}
+
+// onlyImage returns the image of a paragraph holding nothing else, as Markdown writes them
+func onlyImage(n *html.Node) *html.Node {
+	var image *html.Node
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case child.Type == html.ElementNode && child.DataAtom == atom.Img && image == nil:
+			image = child
+		case child.Type == html.TextNode && strings.TrimSpace(child.Data) == "":
+		default:
+			return nil
+		}
+	}
+
+	return image
+}
+
+// convertListItem keeps the paragraph first, as the editor expects
+func convertListItem(n *html.Node) *Node {
+	content := convertBlocks(n)
+	if len(content) == 0 || content[0].Type != "paragraph" {
+		content = append([]*Node{{Type: "paragraph"}}, content...)
+	}
+
+	return &Node{Type: "listItem", Content: content}
+}
+
+func convertChildren(n *html.Node, marks []Mark) []*Node {
+	var nodes []*Node
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		nodes = append(nodes, convertInline(child, marks)...)
+	}
+	return nodes
+}
+
+func convertInline(n *html.Node, marks []Mark) []*Node {
+	switch n.Type {
+	case html.TextNode:
+		return []*Node{newText(utils.CollapseSpaces(n.Data), marks)}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	var mark *Mark
+
+	switch n.DataAtom {
+	case atom.Br:
+		return []*Node{{Type: "hardBreak", Marks: marks}}
+	case atom.Img:
+		// Images are blocks in the editor, they are kept as their description
+		if alt := utils.HTMLAttribute(n, "alt"); alt != "" {
+			return []*Node{newText(alt, marks)}
+		}
+		return nil
+	case atom.Strong, atom.B:
+		mark = &Mark{Type: "bold"}
+	case atom.Em, atom.I:
+		mark = &Mark{Type: "italic"}
+	case atom.S, atom.Del, atom.Strike:
+		mark = &Mark{Type: "strike"}
+	case atom.U, atom.Ins:
+		mark = &Mark{Type: "underline"}
+	case atom.Mark:
+		mark = &Mark{Type: "highlight"}
+	case atom.Code, atom.Kbd, atom.Samp:
+		mark = &Mark{Type: "code"}
+	case atom.A:
+		if href := utils.HTMLAttribute(n, "href"); href != "" {
+			mark = &Mark{Type: "link", Attrs: map[string]any{"href": href, "target": "_blank"}}
+		}
+	case atom.Script, atom.Style, atom.Template:
+		return nil
+	}
+
+	if mark != nil {
+		marks = append(append([]Mark{}, marks...), *mark)
+	}
+
+	return convertChildren(n, marks)
+}
+
+// newTextBlock trims the whitespace around the content and merges the texts with the same marks
+func newTextBlock(nodeType string, inline []*Node) *Node {
+	block := &Node{Type: nodeType}
+
+	for _, node := range inline {
+		if node.Type == "text" {
+			if last := len(block.Content) - 1; last >= 0 && block.Content[last].Type == "text" {
+				previous := block.Content[last]
+
+				// Collapsed spaces do not repeat across elements
+				if strings.HasSuffix(previous.Text, " ") {
+					node.Text = strings.TrimLeft(node.Text, " ")
+				}
+				if sameMarks(previous.Marks, node.Marks) {
+					previous.Text += node.Text
+					continue
+				}
+			}
+			if len(block.Content) == 0 || block.Content[len(block.Content)-1].Type == "hardBreak" {
+				node.Text = strings.TrimLeft(node.Text, " ")
+			}
+			if node.Text == "" {
+				continue
+			}
+		}
+
+		block.Content = append(block.Content, node)
+	}
+
+	// Trailing spaces and line breaks are not shown
+	for len(block.Content) > 0 {
+		last := block.Content[len(block.Content)-1]
+		if last.Type == "text" {
+			last.Text = strings.TrimRight(last.Text, " ")
+			if last.Text != "" {
+				break
+			}
+		} else if last.Type != "hardBreak" {
+			break
+		}
+		block.Content = block.Content[:len(block.Content)-1]
+	}
+
+	return block
+}
+
+func newText(text string, marks []Mark) *Node {
+	return &Node{Type: "text", Text: text, Marks: marks}
+}
+
+func newImage(n *html.Node) *Node {
+	attrs := map[string]any{"src": utils.HTMLAttribute(n, "src"), "alt": nil, "title": nil}
+	if alt := utils.HTMLAttribute(n, "alt"); alt != "" {
+		attrs["alt"] = alt
+	}
+	if title := utils.HTMLAttribute(n, "title"); title != "" {
+		attrs["title"] = title
+	}
+
+	return &Node{Type: "image", Attrs: attrs}
+}
+
+func sameMarks(a, b []Mark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].Type != b[i].Type || attributeValue(a[i].Attrs, "href") != attributeValue(b[i].Attrs, "href") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func attributeValue(attrs map[string]any, key string) any {
+	if attrs == nil {
+		return nil
+	}
+	return attrs[key]
+}
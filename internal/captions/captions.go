@@ -0,0 +1,203 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+// Package captions turns the timed transcript of a recording session into SRT or WebVTT captions.
+package captions
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	FORMAT_SRT = "srt"
+	FORMAT_VTT = "vtt"
+
+	// Usual subtitle limits
+	MAX_LINE_LENGTH = 42
+	MAX_LINES       = 2
+	MIN_DURATION    = 1 * time.Second
+
+	// Confidence of the alignment above which the script text replaces the transcript
+	DEFAULT_MIN_CONFIDENCE = 0.8
+)
+
+// Segment is a transcript of an audio chunk, with the script text it was aligned with
+type Segment struct {
+	Start      int64   `json:"start"` // ms from the start of the recording
+	End        int64   `json:"end"`
+	Text       string  `json:"text"`
+	Script     string  `json:"script"`     // Empty when the transcript was not aligned
+	Confidence float64 `json:"confidence"` // Of the alignment
+}
+
+type Options struct {
+	Format        string  `json:"format"`     // srt or vtt
+	UseScript     bool    `json:"use_script"` // Use the script text of confidently aligned segments
+	MinConfidence float64 `json:"min_confidence"`
+}
+
+type Caption struct {
+	Start time.Duration
+	End   time.Duration
+	Lines []string
+}
+
+// Build splits the segments into captions short enough to be read on screen.
+// The time of a segment is shared between its captions by their length.
+func Build(segments []Segment, options Options) []Caption {
+	minConfidence := options.MinConfidence
+	if minConfidence <= 0 {
+		minConfidence = DEFAULT_MIN_CONFIDENCE
+	}
+
+	var captions []Caption
+
+	for _, segment := range segments {
+		text := segment.Text
+		if options.UseScript && segment.Script != "" && segment.Confidence >= minConfidence {
+			text = segment.Script
+		}
+
+		blocks := splitText(strings.Fields(text))
+		if len(blocks) == 0 {
+			continue
+		}
+
+		total := 0
+		for _, block := range blocks {
+			total += blockLength(block)
+		}
+
+		start := time.Duration(segment.Start) * time.Millisecond
+		duration := time.Duration(segment.End-segment.Start) * time.Millisecond
+
+		elapsed := 0
+		for _, block := range blocks {
+			caption := Caption{
+				Start: start + duration*time.Duration(elapsed)/time.Duration(total),
+				Lines: block,
+			}
+			elapsed += blockLength(block)
+			caption.End = start + duration*time.Duration(elapsed)/time.Duration(total)
+
+			captions = append(captions, caption)
+		}
+	}
+
+	// Short captions are extended up to the next one
+	for i := range captions {
+		if captions[i].End-captions[i].Start >= MIN_DURATION {
+			continue
+		}
+
+		end := captions[i].Start + MIN_DURATION
+		if i+1 < len(captions) {
+			end = min(end, captions[i+1].Start)
+		}
+		captions[i].End = max(captions[i].End, end)
+	}
+
+	return captions
+}
+
+// splitText fills captions of MAX_LINES lines of MAX_LINE_LENGTH characters
+func splitText(words []string) [][]string {
+	var blocks [][]string
+	var lines []string
+	line := ""
+
+	for _, word := range words {
+		if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > MAX_LINE_LENGTH {
+			lines = append(lines, line)
+			line = ""
+
+			if len(lines) == MAX_LINES {
+				blocks = append(blocks, lines)
+				lines = nil
+			}
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+
+	if line != "" {
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		blocks = append(blocks, lines)
+	}
+
+	return blocks
+}
+
+func blockLength(lines []string) int {
+	length := 0
+	for _, line := range lines {
+		length += utf8.RuneCountInString(line)
+	}
+	return max(1, length)
+}
+
+// Write writes the captions in the given format
+func Write(w io.Writer, captions []Caption, format string) error {
+	switch format {
+	case FORMAT_SRT:
+		return WriteSRT(w, captions)
+	case FORMAT_VTT:
+		return WriteVTT(w, captions)
+	default:
+		return fmt.Errorf("unsupported caption format: %s", format)
+	}
+}
+
+func WriteSRT(w io.Writer, captions []Caption) error {
+	for i, caption := range captions {
+		_, err := fmt.Fprintf(w, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatTimestamp(caption.Start, ","),
+			formatTimestamp(caption.End, ","),
+			strings.Join(caption.Lines, "\n"),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func WriteVTT(w io.Writer, captions []Caption) error {
+	if _, err := io.WriteString(w, "WEBVTT\n\n"); err != nil {
+		return err
+	}
+
+	for _, caption := range captions {
+		// "-->" is not allowed in the cue text
+		text := strings.ReplaceAll(strings.Join(caption.Lines, "\n"), "-->", "->")
+
+		_, err := fmt.Fprintf(w, "%s --> %s\n%s\n\n",
+			formatTimestamp(caption.Start, "."),
+			formatTimestamp(caption.End, "."),
+			text,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formatTimestamp returns hh:mm:ss followed by the milliseconds, SRT uses a comma and WebVTT a dot
+func formatTimestamp(d time.Duration, separator string) string {
+	ms := max(0, d.Milliseconds())
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3_600_000, ms/60_000%60, ms/1000%60, separator, ms%1000)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package captions
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatTimestamp(t *testing.T) {
+	cases := []struct {
+		duration time.Duration
+		srt      string
+		vtt      string
+	}{
+		{0, "00:00:00,000", "00:00:00.000"},
+		{999 * time.Millisecond, "00:00:00,999", "00:00:00.999"},
+		{time.Second, "00:00:01,000", "00:00:01.000"},
+		{time.Second + 999*time.Microsecond, "00:00:01,000", "00:00:01.000"},
+		{59*time.Second + 999*time.Millisecond, "00:00:59,999", "00:00:59.999"},
+		{time.Minute, "00:01:00,000", "00:01:00.000"},
+		{time.Hour - time.Millisecond, "00:59:59,999", "00:59:59.999"},
+		{time.Hour, "01:00:00,000", "01:00:00.000"},
+		{time.Hour + time.Millisecond, "01:00:00,001", "01:00:00.001"},
+		{100 * time.Hour, "100:00:00,000", "100:00:00.000"},
+		{-time.Second, "00:00:00,000", "00:00:00.000"},
+	}
+
+	for _, c := range cases {
+		if got := formatTimestamp(c.duration, ","); got != c.srt {
+			t.Errorf("SRT timestamp of %v = %q, want %q", c.duration, got, c.srt)
+		}
+		if got := formatTimestamp(c.duration, "."); got != c.vtt {
+			t.Errorf("WebVTT timestamp of %v = %q, want %q", c.duration, got, c.vtt)
+		}
+	}
+}
+
+var testCaptions = []Caption{
+	{Start: 0, End: 1500 * time.Millisecond, Lines: []string{"Good evening."}},
+	{Start: time.Hour - 500*time.Millisecond, End: time.Hour + 2*time.Second, Lines: []string{"The left arrow -->", "points home."}},
+}
+
+func TestWriteSRT(t *testing.T) {
+	var builder strings.Builder
+	if err := Write(&builder, testCaptions, FORMAT_SRT); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cues are numbered from 1, the text is kept as is
+	want := "1\n00:00:00,000 --> 00:00:01,500\nGood evening.\n\n" +
+		"2\n00:59:59,500 --> 01:00:02,000\nThe left arrow -->\npoints home.\n\n"
+	if got := builder.String(); got != want {
+		t.Errorf("SRT =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWriteVTT(t *testing.T) {
+	var builder strings.Builder
+	if err := Write(&builder, testCaptions, FORMAT_VTT); err != nil {
+		t.Fatal(err)
+	}
+
+	// The header is followed by a blank line, cues are not numbered
+	want := "WEBVTT\n\n" +
+		"00:00:00.000 --> 00:00:01.500\nGood evening.\n\n" +
+		"00:59:59.500 --> 01:00:02.000\nThe left arrow ->\npoints home.\n\n"
+	if got := builder.String(); got != want {
+		t.Errorf("WebVTT =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestWriteVTTWithoutCaptions(t *testing.T) {
+	var builder strings.Builder
+	if err := WriteVTT(&builder, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := builder.String(), "WEBVTT\n\n"; got != want {
+		t.Errorf("WebVTT = %q, want %q", got, want)
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	if err := Write(&strings.Builder{}, testCaptions, "ass"); err == nil {
+		t.Error("Write() = nil, want an unsupported format error")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	segments := []Segment{
+		{Start: 0, End: 4000, Text: "this sentence is long enough to be split over more than one line of captions"},
+		{Start: 4000, End: 4300, Text: "short", Script: "Short.", Confidence: 0.9},
+		{Start: 4600, End: 5000, Text: "final"},
+	}
+
+	captions := Build(segments, Options{Format: FORMAT_SRT, UseScript: true})
+
+	want := []Caption{
+		{Start: 0, End: 4000 * time.Millisecond, Lines: []string{"this sentence is long enough to be split", "over more than one line of captions"}},
+		{Start: 4000 * time.Millisecond, End: 4600 * time.Millisecond, Lines: []string{"Short."}},
+		{Start: 4600 * time.Millisecond, End: 5600 * time.Millisecond, Lines: []string{"final"}},
+	}
+
+	if len(captions) != len(want) {
+		t.Fatalf("got %d captions, want %d: %+v", len(captions), len(want), captions)
+	}
+	for i := range want {
+		if captions[i].Start != want[i].Start || captions[i].End != want[i].End ||
+			strings.Join(captions[i].Lines, "\n") != strings.Join(want[i].Lines, "\n") {
+			t.Errorf("caption %d = %+v, want %+v", i, captions[i], want[i])
+		}
+	}
+}
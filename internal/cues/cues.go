@@ -0,0 +1,58 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+// Package cues parses the stage directions written in scripts between brackets,
+// such as [PAUSE], [SLIDE 4] or [ROLL VT].
+package cues
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+// A directive starts with an uppercase type, so bracketed text such as "[sic]" stays in the script
+var cueRegex = regexp.MustCompile(`\[\s*([A-Z][A-Z0-9_-]+)(?:[\s:]+([^\[\]]*?))?\s*\]`)
+
+type Cue struct {
+	Type     string `json:"type"`     // e.g. SLIDE
+	Argument string `json:"argument"` // e.g. 4, empty for [PAUSE]
+	Text     string `json:"text"`     // Directive as written, e.g. [SLIDE 4]
+	Word     int    `json:"word"`     // Index of the first script word after the cue
+	Start    int    `json:"start"`    // UTF-16 offsets in the text, as counted by the DOM
+	End      int    `json:"end"`
+}
+
+// Parse returns the cues of a text in order. Their word index is set by the aligner.
+func Parse(text string) []Cue {
+	cues := []Cue{}
+
+	// Matches are in order, UTF-16 offsets are counted from the previous one
+	offset, counted := 0, 0
+	utf16Offset := func(byteOffset int) int {
+		offset += len(utf16.Encode([]rune(text[counted:byteOffset])))
+		counted = byteOffset
+		return offset
+	}
+
+	for _, match := range cueRegex.FindAllStringSubmatchIndex(text, -1) {
+		cue := Cue{
+			Type:  text[match[2]:match[3]],
+			Text:  text[match[0]:match[1]],
+			Start: utf16Offset(match[0]),
+			End:   utf16Offset(match[1]),
+		}
+		if match[4] >= 0 {
+			cue.Argument = strings.TrimSpace(text[match[4]:match[5]])
+		}
+
+		cues = append(cues, cue)
+	}
+
+	return cues
+}
+
+// Strip removes the cues of a text
+func Strip(text string) string {
+	return strings.Join(strings.Fields(cueRegex.ReplaceAllString(text, " ")), " ")
+}
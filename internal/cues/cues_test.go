@@ -0,0 +1,90 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package cues
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want []Cue
+	}{
+		{
+			name: "without argument",
+			text: "Good evening. [PAUSE] Welcome.",
+			want: []Cue{{Type: "PAUSE", Text: "[PAUSE]", Start: 14, End: 21}},
+		},
+		{
+			name: "with argument",
+			text: "[SLIDE 4] Next [ROLL VT]",
+			want: []Cue{
+				{Type: "SLIDE", Argument: "4", Text: "[SLIDE 4]", Start: 0, End: 9},
+				{Type: "ROLL", Argument: "VT", Text: "[ROLL VT]", Start: 15, End: 24},
+			},
+		},
+		{
+			name: "argument after a colon",
+			text: "[CAMERA: wide shot ]",
+			want: []Cue{{Type: "CAMERA", Argument: "wide shot", Text: "[CAMERA: wide shot ]", Start: 0, End: 20}},
+		},
+		{
+			name: "spaces inside the brackets",
+			text: "[ SFX_2 door ]",
+			want: []Cue{{Type: "SFX_2", Argument: "door", Text: "[ SFX_2 door ]", Start: 0, End: 14}},
+		},
+		{
+			name: "UTF-16 offsets",
+			text: "Café 🎬 [CUT] là",
+			want: []Cue{{Type: "CUT", Text: "[CUT]", Start: 8, End: 13}},
+		},
+		{
+			name: "offsets across lines",
+			text: "Déjà\n[PAUSE]\n👋 [WAVE]",
+			want: []Cue{
+				{Type: "PAUSE", Text: "[PAUSE]", Start: 5, End: 12},
+				{Type: "WAVE", Text: "[WAVE]", Start: 16, End: 22},
+			},
+		},
+		{
+			name: "not directives",
+			text: "He said [sic] and [A] or [] [Pause]",
+			want: []Cue{},
+		},
+		{
+			name: "nested brackets",
+			text: "[NOTE [PAUSE]]",
+			want: []Cue{{Type: "PAUSE", Text: "[PAUSE]", Start: 6, End: 13}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Parse(c.text); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", c.text, got, c.want)
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"Good evening. [PAUSE] Welcome.", "Good evening. Welcome."},
+		{"[SLIDE 4]\nNext  slide [ROLL VT]", "Next slide"},
+		{"He said [sic].", "He said [sic]."},
+		{"[PAUSE]", ""},
+	}
+
+	for _, c := range cases {
+		if got := Strip(c.text); got != c.want {
+			t.Errorf("Strip(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
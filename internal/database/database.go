@@ -5,6 +5,7 @@ package database
 
 import (
 	"fmt"
+	"log/slog"
 	"myscript/internal/repository"
 	"path/filepath"
 
@@ -60,6 +61,13 @@ func NewMainDatabase(homeDir string) *gorm.DB {
 	db.AutoMigrate(&repository.Config{})
 	db.AutoMigrate(&repository.Page{})
 	db.AutoMigrate(&repository.Cache{})
+	db.AutoMigrate(&repository.ReadingSession{})
+	db.AutoMigrate(&repository.ReadProgress{})
+	db.AutoMigrate(&repository.PageRevision{})
+
+	if err := repository.SetupPageSearch(db); err != nil {
+		slog.Warn("Full-text page search is not available", "error", err)
+	}
 
 	return db
 }
@@ -76,6 +84,8 @@ func NewUnSyncedDatabase(homeDir string) *gorm.DB {
 	db.AutoMigrate(&repository.RemoteApplyFailure{})
 	db.AutoMigrate(&repository.GoogleAuthToken{})
 	db.AutoMigrate(&repository.SyncState{})
+	db.AutoMigrate(&repository.MicrophoneSetting{})
+	db.AutoMigrate(&repository.RecordingSession{})
 
 	return db
 }
@@ -10,6 +10,7 @@ import (
 	"myscript/internal/repository"
 	"reflect"
 	"sync"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -56,6 +57,9 @@ func (s *DatabaseSynchronizer) SynchronizeAll() error {
 		&repository.Config{},
 		&repository.Page{},
 		&repository.Cache{},
+		&repository.ReadingSession{},
+		&repository.ReadProgress{},
+		&repository.PageRevision{},
 	}
 
 	for _, entity := range entities {
@@ -78,6 +82,12 @@ func (s *DatabaseSynchronizer) SynchronizeChangeLog(changeLog repository.ChangeL
 			model = &repository.Page{}
 		case s.GetEntityTableName(&repository.Cache{}):
 			model = &repository.Cache{}
+		case s.GetEntityTableName(&repository.ReadingSession{}):
+			model = &repository.ReadingSession{}
+		case s.GetEntityTableName(&repository.ReadProgress{}):
+			model = &repository.ReadProgress{}
+		case s.GetEntityTableName(&repository.PageRevision{}):
+			model = &repository.PageRevision{}
 		default:
 			return fmt.Errorf("unsupported table name: %s", changeLog.TableName)
 		}
@@ -91,11 +101,30 @@ func (s *DatabaseSynchronizer) SynchronizeChangeLog(changeLog repository.ChangeL
 			return err
 		}
 
-	case repository.OPERATION_DELETE:
-		s.targetDB.
-			Table(changeLog.TableName).
-			Where("id = ?", changeLog.RowID).
-			Delete(nil)
+	case repository.OPERATION_DELETE, repository.OPERATION_PURGE:
+		if changeLog.Operation == repository.OPERATION_DELETE &&
+			s.targetDB.Migrator().HasColumn(changeLog.TableName, "deleted_at") {
+			// Soft deleted rows stay restorable, from the time they were deleted on the other device
+			var data struct{ DeletedAt *time.Time }
+			if json.Unmarshal([]byte(changeLog.NewData), &data) != nil || data.DeletedAt == nil {
+				now := time.Now()
+				data.DeletedAt = &now
+			}
+
+			s.targetDB.
+				Table(changeLog.TableName).
+				Where("id = ?", changeLog.RowID).
+				Update("deleted_at", *data.DeletedAt)
+		} else {
+			s.targetDB.
+				Table(changeLog.TableName).
+				Where("id = ?", changeLog.RowID).
+				Delete(nil)
+		}
+
+		if changeLog.TableName == s.GetEntityTableName(&repository.Page{}) {
+			repository.UnindexPage(s.targetDB, changeLog.RowID)
+		}
 
 		s.addAffectedTable(changeLog.TableName, nil)
 
@@ -132,6 +161,7 @@ func (s *DatabaseSynchronizer) synchronizeEntity(entity interface{}, records []i
 					return err
 				}
 
+				s.indexRecord(tx, record)
 				s.addAffectedTable(tableName, record)
 			}
 			return nil
@@ -155,26 +185,40 @@ func (s *DatabaseSynchronizer) synchronizeEntity(entity interface{}, records []i
 					return err
 				}
 
+				s.indexRecord(tx, record)
 				s.addAffectedTable(tableName, record)
 			}
 			return nil
 		}
 
-		// Fallback to basic save
+		// Fallback to basic save, soft deleted rows included so restores are applied
 		for _, record := range records {
-			if err := tx.Table(tableName).Save(record).Error; err != nil {
+			if err := tx.Table(tableName).Unscoped().Save(record).Error; err != nil {
 				slog.Error("DatabaseSynchronizer[synchronizeEntity] Failed to save record",
 					"table", tableName, "error", err,
 				)
 				return err
 			}
 
+			s.indexRecord(tx, record)
 			s.addAffectedTable(tableName, record)
 		}
 		return nil
 	})
 }
 
+// indexRecord updates the search index of the synchronized pages, hooks are skipped
+func (s *DatabaseSynchronizer) indexRecord(tx *gorm.DB, record interface{}) {
+	page, ok := record.(*repository.Page)
+	if !ok {
+		return
+	}
+
+	if err := repository.IndexPage(tx, page); err != nil {
+		slog.Error("DatabaseSynchronizer[indexRecord] Failed to index page", "id", page.ID, "error", err)
+	}
+}
+
 func (s *DatabaseSynchronizer) GetAffectedTables() AffectedTables {
 	return s.affectedTables
 }
@@ -199,6 +243,12 @@ func (s *DatabaseSynchronizer) addAffectedTable(tableName string, record interfa
 }
 
 func (s *DatabaseSynchronizer) synchronizeSourceEntity(entity interface{}) error {
+	// Snapshots uploaded by an older client do not have the tables added since, they have no records
+	if !s.sourceDB.Migrator().HasTable(entity) {
+		slog.Debug("DatabaseSynchronizer[synchronizeSourceEntity] Table missing in the source, skipped", "entity", fmt.Sprintf("%T", entity))
+		return nil
+	}
+
 	// Schema compatibility check
 	if compatible, err := s.areSchemasCompatible(entity); !compatible || err != nil {
 		if err != nil {
@@ -227,6 +277,10 @@ func (s *DatabaseSynchronizer) getSyncRules(entity interface{}) EntitySyncRule {
 			ConflictColumns:       []string{"key"},
 			OnConflictOmitColumns: []string{"id"},
 		}
+	case *repository.ReadProgress:
+		return EntitySyncRule{
+			Strategy: s.syncReadProgressStrategy,
+		}
 	default:
 		return EntitySyncRule{}
 	}
@@ -385,3 +439,36 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// syncReadProgressStrategy keeps the latest progress of each page and device,
+// so an older change never overwrites a newer position
+func (s *DatabaseSynchronizer) syncReadProgressStrategy(entity interface{}) error {
+	progress, ok := entity.(*repository.ReadProgress)
+	if !ok {
+		return fmt.Errorf("invalid type for read progress strategy")
+	}
+
+	var existing repository.ReadProgress
+	err := s.targetDB.Unscoped().
+		Where("page_id = ? AND device_id = ?", progress.PageID, progress.DeviceID).
+		First(&existing).Error
+
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return s.targetDB.Create(progress).Error
+		}
+		return err
+	}
+
+	if !progress.ReadAt.After(existing.ReadAt) {
+		return nil
+	}
+
+	return s.targetDB.Unscoped().Model(&existing).Updates(repository.MapUpdate{
+		"progress":   progress.Progress,
+		"total":      progress.Total,
+		"read_at":    progress.ReadAt,
+		"updated_at": progress.UpdatedAt,
+		"deleted_at": progress.DeletedAt,
+	}).Error
+}
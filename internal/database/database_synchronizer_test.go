@@ -0,0 +1,76 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package database
+
+import (
+	"myscript/internal/repository"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// newOlderSnapshot creates a snapshot uploaded by a client without the reading sessions,
+// read progress and page revisions tables
+func newOlderSnapshot(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := MountDatabase(filepath.Join(t.TempDir(), "snapshot.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.AutoMigrate(&repository.Config{}, &repository.Page{}, &repository.Cache{}); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestSynchronizeAllFromOlderSnapshot(t *testing.T) {
+	source := newOlderSnapshot(t)
+	target := NewMainDatabase(t.TempDir())
+
+	page := repository.Page{Title: "Opening", HtmlContent: "<p>Good evening.</p>"}
+	if err := source.Session(&gorm.Session{SkipHooks: true}).Create(&page).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	synchronizer := NewDatabaseSynchronizer(source, target)
+	if err := synchronizer.SynchronizeAll(); err != nil {
+		t.Fatalf("SynchronizeAll() = %v", err)
+	}
+
+	var restored repository.Page
+	if err := target.First(&restored, "id = ?", page.ID).Error; err != nil {
+		t.Fatalf("page not restored: %v", err)
+	}
+	if restored.Title != page.Title {
+		t.Errorf("restored title = %q, want %q", restored.Title, page.Title)
+	}
+
+	for _, entity := range []interface{}{&repository.ReadingSession{}, &repository.ReadProgress{}, &repository.PageRevision{}} {
+		var count int64
+		if err := target.Model(entity).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != 0 {
+			t.Errorf("%T has %d records, want 0", entity, count)
+		}
+	}
+}
+
+func TestSynchronizeAllIncompatibleSchema(t *testing.T) {
+	source := newOlderSnapshot(t)
+	target := NewMainDatabase(t.TempDir())
+
+	// A table of the source with a column unknown to this client is still rejected
+	if err := source.Exec("ALTER TABLE caches ADD COLUMN unknown_column TEXT").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewDatabaseSynchronizer(source, target).SynchronizeAll(); err == nil {
+		t.Error("SynchronizeAll() = nil, want an incompatible schema error")
+	}
+}
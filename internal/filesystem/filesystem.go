@@ -40,6 +40,17 @@ func createDirectoryInHome(dirName string) string {
 	return newDirPath
 }
 
+// GetHomeSubDir returns a directory inside the home directory, creating it if needed
+func GetHomeSubDir(name string) (string, error) {
+	dir := filepath.Join(HOME_DIR, name)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
 func init() {
 	HOME_DIR = createDirectoryInHome(homeDirName)
 }
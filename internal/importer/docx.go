@@ -0,0 +1,519 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package importer
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"html"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	wordNamespace       = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	strictWordNamespace = "http://purl.oclc.org/ooxml/wordprocessingml/main"
+	compatNamespace     = "http://schemas.openxmlformats.org/markup-compatibility/2006"
+
+	// Default location of the main part, when the package relationships do not name it
+	DOCX_DOCUMENT_PART = "word/document.xml"
+	DOCX_STYLES_PART   = "word/styles.xml"
+	// Parts bigger than this are not read, so a crafted archive cannot exhaust the memory
+	DOCX_MAX_PART_SIZE = 64 << 20
+)
+
+var ErrInvalidDocx = errors.New("the file is not a valid Word document")
+
+// xmlNode is an element of an OOXML part
+type xmlNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Children []*xmlNode
+	Text     string
+}
+
+func (n *xmlNode) isWord(local string) bool {
+	return n.Name.Local == local && (n.Name.Space == wordNamespace || n.Name.Space == strictWordNamespace)
+}
+
+// child returns the first Word element with the given name
+func (n *xmlNode) child(local string) *xmlNode {
+	for _, child := range n.Children {
+		if child.isWord(local) {
+			return child
+		}
+	}
+	return nil
+}
+
+// value returns the "val" attribute of a Word element
+func (n *xmlNode) value() string {
+	return n.attribute("val")
+}
+
+func (n *xmlNode) attribute(local string) string {
+	for _, attr := range n.Attrs {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// toggle reads an on/off property, which is on without a value
+func (n *xmlNode) toggle() bool {
+	switch n.value() {
+	case "0", "false", "off":
+		return false
+	}
+	return true
+}
+
+// docxStyle holds what matters of a paragraph or character style
+type docxStyle struct {
+	name    string
+	basedOn string
+	level   int
+	bold    *bool
+	italic  *bool
+}
+
+type docxReader struct {
+	styles  map[string]*docxStyle
+	report  *report
+	title   string
+	builder strings.Builder
+}
+
+// docxRun is a piece of text with its formatting
+type docxRun struct {
+	text   string
+	bold   bool
+	italic bool
+}
+
+// ReadDocx reads a Word document as a page, keeping its headings, paragraphs, bold and italic.
+// The name is used when the document has no title or heading.
+func ReadDocx(filePath string, name string) (*Result, error) {
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, ErrInvalidDocx
+	}
+	defer archive.Close()
+
+	return readDocx(&archive.Reader, name)
+}
+
+func readDocx(archive *zip.Reader, name string) (*Result, error) {
+	document, err := readPart(archive, documentPart(archive))
+	if err != nil {
+		return nil, err
+	}
+	body := document.child("body")
+	if !document.isWord("document") || body == nil {
+		return nil, ErrInvalidDocx
+	}
+
+	r := &docxReader{styles: map[string]*docxStyle{}, report: &report{}}
+
+	// Without styles, headings are found from the usual style IDs
+	if styles, err := readPart(archive, DOCX_STYLES_PART); err == nil {
+		r.readStyles(styles)
+	}
+
+	r.readBlocks(body)
+
+	return newResult(r.title, name, r.builder.String(), r.report), nil
+}
+
+// documentPart finds the main part from the package relationships
+func documentPart(archive *zip.Reader) string {
+	relationships, err := readPart(archive, "_rels/.rels")
+	if err != nil {
+		return DOCX_DOCUMENT_PART
+	}
+
+	for _, relationship := range relationships.Children {
+		if relationship.Name.Local != "Relationship" {
+			continue
+		}
+
+		var relationshipType, target string
+		for _, attr := range relationship.Attrs {
+			switch attr.Name.Local {
+			case "Type":
+				relationshipType = attr.Value
+			case "Target":
+				target = attr.Value
+			}
+		}
+
+		if strings.HasSuffix(relationshipType, "/officeDocument") && target != "" {
+			return strings.TrimPrefix(path.Clean("/"+target), "/")
+		}
+	}
+
+	return DOCX_DOCUMENT_PART
+}
+
+func readPart(archive *zip.Reader, name string) (*xmlNode, error) {
+	file, err := archive.Open(name)
+	if err != nil {
+		return nil, ErrInvalidDocx
+	}
+	defer file.Close()
+
+	return parseXML(io.LimitReader(file, DOCX_MAX_PART_SIZE))
+}
+
+// parseXML reads a part as a tree of elements, text is kept on the elements holding it
+func parseXML(reader io.Reader) (*xmlNode, error) {
+	decoder := xml.NewDecoder(reader)
+	root := &xmlNode{}
+	stack := []*xmlNode{root}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidDocx
+		}
+
+		parent := stack[len(stack)-1]
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{Name: token.Name, Attrs: token.Attr}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.Text += string(token)
+		}
+	}
+
+	if len(root.Children) == 0 {
+		return nil, ErrInvalidDocx
+	}
+
+	return root.Children[0], nil
+}
+
+func (r *docxReader) readStyles(styles *xmlNode) {
+	for _, node := range styles.Children {
+		if !node.isWord("style") {
+			continue
+		}
+
+		ID := node.attribute("styleId")
+		style := &docxStyle{}
+		if name := node.child("name"); name != nil {
+			style.name = strings.ToLower(name.value())
+		}
+		if basedOn := node.child("basedOn"); basedOn != nil {
+			style.basedOn = basedOn.value()
+		}
+		if properties := node.child("pPr"); properties != nil {
+			if outline := properties.child("outlineLvl"); outline != nil {
+				if level, err := strconv.Atoi(outline.value()); err == nil && level < 9 {
+					style.level = level + 1
+				}
+			}
+		}
+		if properties := node.child("rPr"); properties != nil {
+			if node := properties.child("b"); node != nil {
+				bold := node.toggle()
+				style.bold = &bold
+			}
+			if node := properties.child("i"); node != nil {
+				italic := node.toggle()
+				style.italic = &italic
+			}
+		}
+
+		r.styles[ID] = style
+	}
+}
+
+// headingLevel returns the heading level of a paragraph style, 0 for body text
+func (r *docxReader) headingLevel(styleID string) int {
+	visited := map[string]bool{}
+
+	for ID := styleID; ID != "" && !visited[ID]; {
+		visited[ID] = true
+
+		style := r.styles[ID]
+		name := strings.ToLower(ID)
+		if style != nil {
+			if style.level > 0 {
+				return min(style.level, 6)
+			}
+			name = style.name
+		}
+
+		if name == "title" {
+			return 1
+		}
+		if level, ok := strings.CutPrefix(strings.ReplaceAll(name, " ", ""), "heading"); ok {
+			if level, err := strconv.Atoi(level); err == nil && level > 0 {
+				return min(level, 6)
+			}
+		}
+
+		if style == nil {
+			break
+		}
+		ID = style.basedOn
+	}
+
+	return 0
+}
+
+// styleFormat returns the bold and italic properties of a character style
+func (r *docxReader) styleFormat(styleID string) (bold *bool, italic *bool) {
+	visited := map[string]bool{}
+
+	for ID := styleID; ID != "" && !visited[ID]; {
+		visited[ID] = true
+
+		style := r.styles[ID]
+		if style == nil {
+			break
+		}
+		if bold == nil {
+			bold = style.bold
+		}
+		if italic == nil {
+			italic = style.italic
+		}
+		ID = style.basedOn
+	}
+
+	return bold, italic
+}
+
+// readBlocks reads the paragraphs of the body, a table cell or a content control
+func (r *docxReader) readBlocks(parent *xmlNode) {
+	for _, node := range parent.Children {
+		if node.Name.Space == compatNamespace && node.Name.Local == "AlternateContent" {
+			r.readAlternateContent(node, r.readBlocks)
+			continue
+		}
+		if node.Name.Space != wordNamespace && node.Name.Space != strictWordNamespace {
+			continue
+		}
+
+		switch node.Name.Local {
+		case "p":
+			r.readParagraph(node)
+
+		case "tbl":
+			// The text of the cells is kept, one paragraph after the other
+			r.report.add("table")
+			for _, row := range node.Children {
+				for _, cell := range row.Children {
+					if cell.isWord("tc") {
+						r.readBlocks(cell)
+					}
+				}
+			}
+
+		case "sdt":
+			if content := node.child("sdtContent"); content != nil {
+				r.readBlocks(content)
+			}
+
+		case "customXml", "ins", "moveTo":
+			r.readBlocks(node)
+		}
+	}
+}
+
+// readAlternateContent reads the first choice of a compatibility block, the others are fallbacks
+func (r *docxReader) readAlternateContent(node *xmlNode, read func(*xmlNode)) {
+	for _, choice := range node.Children {
+		if choice.Name.Space == compatNamespace && (choice.Name.Local == "Choice" || choice.Name.Local == "Fallback") {
+			read(choice)
+			return
+		}
+	}
+}
+
+func (r *docxReader) readParagraph(paragraph *xmlNode) {
+	level := 0
+
+	if properties := paragraph.child("pPr"); properties != nil {
+		if style := properties.child("pStyle"); style != nil {
+			level = r.headingLevel(style.value())
+		}
+		if outline := properties.child("outlineLvl"); outline != nil {
+			if outlineLevel, err := strconv.Atoi(outline.value()); err == nil && outlineLevel < 9 {
+				level = min(outlineLevel+1, 6)
+			}
+		}
+		if properties.child("numPr") != nil {
+			r.report.add("list numbering")
+		}
+	}
+
+	var runs []docxRun
+	r.readRuns(paragraph, false, false, &runs)
+
+	content := runsHTML(runs)
+
+	if level == 0 {
+		r.builder.WriteString("<p>" + content + "</p>")
+		return
+	}
+
+	// Headings are kept on a single line, their formatting is the heading itself
+	text := ""
+	for _, run := range runs {
+		text += run.text
+	}
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\n", " "))
+	if text == "" {
+		return
+	}
+
+	if r.title == "" {
+		r.title = text
+	}
+
+	tag := "h" + strconv.Itoa(level)
+	r.builder.WriteString("<" + tag + ">" + html.EscapeString(text) + "</" + tag + ">")
+}
+
+// readRuns reads the text of a paragraph with its formatting.
+// Hyperlinks, fields and tracked insertions are read as their text.
+func (r *docxReader) readRuns(parent *xmlNode, bold bool, italic bool, runs *[]docxRun) {
+	for _, node := range parent.Children {
+		if node.Name.Space == compatNamespace && node.Name.Local == "AlternateContent" {
+			r.readAlternateContent(node, func(choice *xmlNode) { r.readRuns(choice, bold, italic, runs) })
+			continue
+		}
+		if node.Name.Space != wordNamespace && node.Name.Space != strictWordNamespace {
+			continue
+		}
+
+		switch node.Name.Local {
+		case "r":
+			r.readRun(node, bold, italic, runs)
+
+		case "hyperlink":
+			r.report.add("hyperlink")
+			r.readRuns(node, bold, italic, runs)
+
+		case "fldSimple", "smartTag", "customXml", "ins", "moveTo", "dir", "bdo":
+			r.readRuns(node, bold, italic, runs)
+
+		case "sdt":
+			if content := node.child("sdtContent"); content != nil {
+				r.readRuns(content, bold, italic, runs)
+			}
+
+		case "oMath", "oMathPara":
+			r.report.add("equation")
+		}
+	}
+}
+
+func (r *docxReader) readRun(run *xmlNode, bold bool, italic bool, runs *[]docxRun) {
+	if properties := run.child("rPr"); properties != nil {
+		if style := properties.child("rStyle"); style != nil {
+			styleBold, styleItalic := r.styleFormat(style.value())
+			if styleBold != nil {
+				bold = *styleBold
+			}
+			if styleItalic != nil {
+				italic = *styleItalic
+			}
+		}
+		if node := properties.child("b"); node != nil {
+			bold = node.toggle()
+		}
+		if node := properties.child("i"); node != nil {
+			italic = node.toggle()
+		}
+	}
+
+	add := func(text string) {
+		*runs = append(*runs, docxRun{text: text, bold: bold, italic: italic})
+	}
+
+	for _, node := range run.Children {
+		if node.Name.Space == compatNamespace && node.Name.Local == "AlternateContent" {
+			r.report.add("drawing")
+			continue
+		}
+		if node.Name.Space != wordNamespace && node.Name.Space != strictWordNamespace {
+			continue
+		}
+
+		switch node.Name.Local {
+		case "t":
+			add(node.Text)
+		case "tab", "ptab":
+			add(" ")
+		case "noBreakHyphen":
+			add("-")
+		case "cr":
+			add("\n")
+		case "br":
+			if breakType := node.attribute("type"); breakType == "page" || breakType == "column" {
+				r.report.add(breakType + " break")
+			} else {
+				add("\n")
+			}
+		case "drawing", "pict":
+			r.report.add("drawing")
+		case "object", "embeddedObject":
+			r.report.add("embedded object")
+		case "footnoteReference":
+			r.report.add("footnote")
+		case "endnoteReference":
+			r.report.add("endnote")
+		case "commentReference":
+			r.report.add("comment")
+		case "sym":
+			r.report.add("symbol")
+		}
+	}
+}
+
+// runsHTML writes the runs of a paragraph, neighbours with the same formatting share their tags
+func runsHTML(runs []docxRun) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(runs); {
+		run := runs[i]
+
+		text := ""
+		for ; i < len(runs) && runs[i].bold == run.bold && runs[i].italic == run.italic; i++ {
+			text += runs[i].text
+		}
+
+		content := strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+		if strings.TrimSpace(text) != "" {
+			if run.italic {
+				content = "<em>" + content + "</em>"
+			}
+			if run.bold {
+				content = "<strong>" + content + "</strong>"
+			}
+		}
+
+		builder.WriteString(content)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package importer
+
+import (
+	"html"
+	"myscript/internal/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	boneyardRegex           = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	noteRegex               = regexp.MustCompile(`(?s)\[\[.*?\]\]`)
+	titleKeyRegex           = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*):(.*)$`)
+	sceneHeadingRegex       = regexp.MustCompile(`(?i)^(INT|EXT|EST|INT\.?/EXT|I/E)[. ]`)
+	forcedSceneHeadingRegex = regexp.MustCompile(`^\.[^.]`)
+	sceneNumberRegex        = regexp.MustCompile(`\s*#([\w.-]+)#$`)
+	pageBreakRegex          = regexp.MustCompile(`^={3,}$`)
+	sectionRegex            = regexp.MustCompile(`^(#+)\s*(.*)$`)
+	boldItalicRegex         = regexp.MustCompile(`\*\*\*(\S(?:.*?\S)?)\*\*\*`)
+	boldRegex               = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	italicRegex             = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+	underlineRegex          = regexp.MustCompile(`_(\S(?:.*?\S)?)_`)
+	fountainEscapes         = strings.NewReplacer(`\*`, "\x00a", `\_`, "\x00u")
+	fountainUnescapes       = strings.NewReplacer("\x00a", "*", "\x00u", "_")
+	characterExtension      = regexp.MustCompile(`\s*\(.*\)\s*$`)
+)
+
+// Fountain elements, used as the class of the HTML they become
+const (
+	FOUNTAIN_SCENE_HEADING = "scene-heading"
+	FOUNTAIN_ACTION        = "action"
+	FOUNTAIN_CHARACTER     = "character"
+	FOUNTAIN_DIALOGUE      = "dialogue"
+	FOUNTAIN_PARENTHETICAL = "parenthetical"
+	FOUNTAIN_TRANSITION    = "transition"
+	FOUNTAIN_CENTERED      = "centered"
+	FOUNTAIN_LYRICS        = "lyrics"
+	FOUNTAIN_TITLE_PAGE    = "title-page"
+)
+
+type fountainParser struct {
+	report  *report
+	builder strings.Builder
+	title   string
+
+	// Lines of the action or dialogue being read, they become a single paragraph
+	element string
+	lines   []string
+}
+
+// ReadFountain reads a Fountain screenplay as a page.
+// Scene headings become headings, characters, parentheticals and dialogue get their own
+// paragraphs, marked with the class of their element.
+func ReadFountain(name string, text string) *Result {
+	p := &fountainParser{report: &report{}}
+
+	text = boneyardRegex.ReplaceAllStringFunc(text, func(string) string {
+		p.report.add("boneyard")
+		return ""
+	})
+	text = noteRegex.ReplaceAllStringFunc(text, func(string) string {
+		p.report.add("note")
+		return ""
+	})
+
+	lines := strings.Split(text, "\n")
+	lines = p.parseTitlePage(lines)
+	p.parseBody(lines)
+
+	return newResult(p.title, name, p.builder.String(), p.report)
+}
+
+// parseTitlePage reads the "Key: value" lines starting the screenplay and returns the lines after them
+func (p *fountainParser) parseTitlePage(lines []string) []string {
+	if len(lines) == 0 || !titleKeyRegex.MatchString(lines[0]) {
+		return lines
+	}
+
+	type field struct {
+		key    string
+		values []string
+	}
+	var fields []*field
+
+	i := 0
+	for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+		line := lines[i]
+
+		// Values can continue on indented lines
+		if len(fields) > 0 && (strings.HasPrefix(line, "   ") || strings.HasPrefix(line, "\t")) {
+			last := fields[len(fields)-1]
+			last.values = append(last.values, strings.TrimSpace(line))
+			continue
+		}
+
+		match := titleKeyRegex.FindStringSubmatch(line)
+		if match == nil {
+			// Not a title page after all
+			return lines
+		}
+
+		f := &field{key: strings.ToLower(strings.TrimSpace(match[1]))}
+		if value := strings.TrimSpace(match[2]); value != "" {
+			f.values = append(f.values, value)
+		}
+		fields = append(fields, f)
+	}
+
+	for _, f := range fields {
+		if len(f.values) == 0 {
+			continue
+		}
+
+		content := make([]string, len(f.values))
+		for i, value := range f.values {
+			content[i] = fountainInline(value)
+		}
+
+		if f.key == "title" {
+			if p.title == "" {
+				p.title = utils.HTMLToText(strings.Join(content, " "))
+			}
+			p.builder.WriteString("<h1>" + strings.Join(content, "<br>") + "</h1>")
+			continue
+		}
+
+		p.writeParagraph(FOUNTAIN_TITLE_PAGE, strings.Join(content, "<br>"))
+	}
+	p.builder.WriteString("<hr>")
+
+	return lines[i:]
+}
+
+func (p *fountainParser) parseBody(lines []string) {
+	blank := func(i int) bool {
+		return i < 0 || i >= len(lines) || strings.TrimSpace(lines[i]) == ""
+	}
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+
+		if line == "" {
+			// Two spaces keep a blank line inside dialogue
+			if raw == "  " && p.element == FOUNTAIN_DIALOGUE {
+				p.lines = append(p.lines, "")
+				continue
+			}
+			p.flush()
+			p.element = ""
+			continue
+		}
+
+		// Dialogue follows its character until a blank line
+		if p.element == FOUNTAIN_DIALOGUE || p.element == FOUNTAIN_PARENTHETICAL || p.element == FOUNTAIN_CHARACTER {
+			if strings.HasPrefix(line, "(") && strings.HasSuffix(line, ")") {
+				p.flush()
+				p.writeParagraph(FOUNTAIN_PARENTHETICAL, "<em>"+fountainInline(line)+"</em>")
+				p.element = FOUNTAIN_PARENTHETICAL
+				continue
+			}
+			p.element = FOUNTAIN_DIALOGUE
+			p.lines = append(p.lines, line)
+			continue
+		}
+
+		switch {
+		case pageBreakRegex.MatchString(line):
+			p.flush()
+			p.builder.WriteString("<hr>")
+
+		case strings.HasPrefix(line, "#"):
+			p.flush()
+			match := sectionRegex.FindStringSubmatch(line)
+			level := strconv.Itoa(min(len(match[1]), 6))
+			p.builder.WriteString("<h" + level + ">" + fountainInline(match[2]) + "</h" + level + ">")
+
+		case strings.HasPrefix(line, "=") && !strings.HasPrefix(line, "=="):
+			// Synopses are notes for the writer, they are not part of the script
+			p.report.add("synopsis")
+
+		case blank(i-1) && (sceneHeadingRegex.MatchString(line) || forcedSceneHeadingRegex.MatchString(line)):
+			p.flush()
+			p.writeSceneHeading(strings.TrimPrefix(line, "."))
+
+		case strings.HasPrefix(line, ">") && strings.HasSuffix(line, "<"):
+			p.flush()
+			p.writeParagraph(FOUNTAIN_CENTERED, fountainInline(strings.TrimSpace(line[1:len(line)-1])))
+
+		case strings.HasPrefix(line, ">"):
+			p.flush()
+			p.writeParagraph(FOUNTAIN_TRANSITION, "<strong>"+fountainInline(strings.TrimSpace(line[1:]))+"</strong>")
+
+		case blank(i-1) && blank(i+1) && strings.HasSuffix(line, "TO:") && isUpper(line):
+			p.flush()
+			p.writeParagraph(FOUNTAIN_TRANSITION, "<strong>"+fountainInline(line)+"</strong>")
+
+		case strings.HasPrefix(line, "~"):
+			p.flush()
+			p.writeParagraph(FOUNTAIN_LYRICS, "<em>"+fountainInline(strings.TrimSpace(line[1:]))+"</em>")
+
+		case blank(i-1) && !blank(i+1) && !strings.HasPrefix(line, "!") && isCharacter(line):
+			p.flush()
+			p.writeCharacter(line)
+
+		default:
+			if p.element != FOUNTAIN_ACTION {
+				p.flush()
+				p.element = FOUNTAIN_ACTION
+			}
+			p.lines = append(p.lines, strings.TrimPrefix(line, "!"))
+		}
+	}
+
+	p.flush()
+}
+
+// isCharacter tells whether a line names a character: forced with "@", or in capitals
+func isCharacter(line string) bool {
+	if strings.HasPrefix(line, "@") {
+		return true
+	}
+
+	name := characterExtension.ReplaceAllString(strings.TrimSuffix(line, "^"), "")
+	return isUpper(name)
+}
+
+// isUpper tells whether a text has letters, all in capitals
+func isUpper(text string) bool {
+	letters := false
+	for _, r := range text {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters = true
+		}
+	}
+	return letters
+}
+
+func (p *fountainParser) writeSceneHeading(line string) {
+	attributes := ` class="` + FOUNTAIN_SCENE_HEADING + `"`
+
+	if match := sceneNumberRegex.FindStringSubmatch(line); match != nil {
+		attributes += ` data-scene-number="` + html.EscapeString(match[1]) + `"`
+		line = line[:len(line)-len(match[0])]
+	}
+
+	p.builder.WriteString("<h2" + attributes + ">" + fountainInline(line) + "</h2>")
+}
+
+func (p *fountainParser) writeCharacter(line string) {
+	line = strings.TrimPrefix(line, "@")
+
+	if name, dual := strings.CutSuffix(line, "^"); dual {
+		// Both speeches are kept, one after the other
+		p.report.add("dual dialogue")
+		line = strings.TrimSpace(name)
+	}
+
+	p.writeParagraph(FOUNTAIN_CHARACTER, "<strong>"+fountainInline(line)+"</strong>")
+	p.element = FOUNTAIN_CHARACTER
+}
+
+func (p *fountainParser) writeParagraph(class string, content string) {
+	p.builder.WriteString(`<p class="` + class + `">` + content + "</p>")
+}
+
+// flush writes the action or dialogue being read
+func (p *fountainParser) flush() {
+	if len(p.lines) == 0 {
+		return
+	}
+
+	content := make([]string, len(p.lines))
+	for i, line := range p.lines {
+		content[i] = fountainInline(line)
+	}
+
+	p.writeParagraph(p.element, strings.Join(content, "<br>"))
+	p.lines = nil
+}
+
+// fountainInline converts the emphasis of a line: ***bold italic***, **bold**, *italic* and _underline_
+func fountainInline(text string) string {
+	text = html.EscapeString(fountainEscapes.Replace(text))
+
+	text = boldItalicRegex.ReplaceAllString(text, "<strong><em>$1</em></strong>")
+	text = boldRegex.ReplaceAllString(text, "<strong>$1</strong>")
+	text = italicRegex.ReplaceAllString(text, "<em>$1</em>")
+	text = underlineRegex.ReplaceAllString(text, "<u>$1</u>")
+
+	return fountainUnescapes.Replace(text)
+}
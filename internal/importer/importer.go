@@ -0,0 +1,139 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+// Package importer reads scripts written in other tools (Word documents, plain text
+// and Fountain screenplays) as pages, reporting what could not be kept.
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"myscript/internal/blocks"
+	"myscript/internal/repository"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const (
+	FORMAT_DOCX     = "docx"
+	FORMAT_TEXT     = "text"
+	FORMAT_FOUNTAIN = "fountain"
+)
+
+// Formats of the files that can be imported, by extension
+var EXTENSIONS = map[string]string{
+	".docx":     FORMAT_DOCX,
+	".txt":      FORMAT_TEXT,
+	".text":     FORMAT_TEXT,
+	".fountain": FORMAT_FOUNTAIN,
+}
+
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// Unsupported is an element of a document that could not be imported
+type Unsupported struct {
+	Element string `json:"element"`
+	Count   int    `json:"count"`
+}
+
+type Result struct {
+	Page        repository.Page `json:"page"`
+	Unsupported []Unsupported   `json:"unsupported"`
+}
+
+// ImportFile reads a file as a page, its format is found from its extension
+func ImportFile(path string) (*Result, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	format, ok := EXTENSIONS[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if format == FORMAT_DOCX {
+		return ReadDocx(path, name)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FORMAT_FOUNTAIN {
+		return ReadFountain(name, decodeText(data)), nil
+	}
+	return ReadText(name, decodeText(data)), nil
+}
+
+// decodeText reads UTF-8 or UTF-16 text with a byte order mark.
+// Text that is not valid UTF-8 is read as Windows-1252, as older editors write it.
+func decodeText(data []byte) string {
+	var decoder *encoding.Decoder
+
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}), bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		decoder = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()
+	case utf8.Valid(data):
+		decoder = unicode.UTF8BOM.NewDecoder()
+	default:
+		decoder = charmap.Windows1252.NewDecoder()
+	}
+
+	decoded, err := decoder.Bytes(data)
+	if err != nil {
+		return normalizeNewlines(strings.ToValidUTF8(string(data), "\uFFFD"))
+	}
+
+	return normalizeNewlines(string(decoded))
+}
+
+func normalizeNewlines(text string) string {
+	return strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(text)
+}
+
+// report counts the unsupported elements of a document, in the order they are found
+type report struct {
+	counts   map[string]int
+	elements []string
+}
+
+func (r *report) add(element string) {
+	if r.counts == nil {
+		r.counts = map[string]int{}
+	}
+	if r.counts[element] == 0 {
+		r.elements = append(r.elements, element)
+	}
+	r.counts[element]++
+}
+
+func (r *report) unsupported() []Unsupported {
+	unsupported := []Unsupported{}
+	for _, element := range r.elements {
+		unsupported = append(unsupported, Unsupported{Element: element, Count: r.counts[element]})
+	}
+	return unsupported
+}
+
+// newResult builds the page of an imported document, the name is used when it has no title
+func newResult(title string, name string, content string, r *report) *Result {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		title = name
+	}
+
+	return &Result{
+		Page: repository.Page{
+			Title:       title,
+			HtmlContent: content,
+			Blocks:      blocks.FromHTML(content),
+		},
+		Unsupported: r.unsupported(),
+	}
+}
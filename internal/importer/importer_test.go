@@ -0,0 +1,144 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package importer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestImportDocx(t *testing.T) {
+	result, err := ImportFile(filepath.Join("testdata", "script.docx"))
+	if err != nil {
+		t.Fatalf("ImportFile() = %v", err)
+	}
+
+	if want := "Opening"; result.Page.Title != want {
+		t.Errorf("title = %q, want %q", result.Page.Title, want)
+	}
+
+	want := "<h1>Opening</h1>" +
+		"<p>Good evening, <strong>everyone</strong> and <em>welcome</em>.</p>" +
+		"<h2>First part</h2>" +
+		"<p>Line one<br>line two</p>" +
+		"<p>See the site</p>" +
+		"<p>Cell</p>"
+	if result.Page.HtmlContent != want {
+		t.Errorf("content = %q, want %q", result.Page.HtmlContent, want)
+	}
+
+	unsupported := []Unsupported{{"page break", 1}, {"hyperlink", 1}, {"table", 1}}
+	if !reflect.DeepEqual(result.Unsupported, unsupported) {
+		t.Errorf("unsupported = %+v, want %+v", result.Unsupported, unsupported)
+	}
+}
+
+func TestReadText(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"single paragraph", "Good evening.", "<p>Good evening.</p>"},
+		{"blank line", "Good evening.\n\nWelcome.", "<p>Good evening.</p><p>Welcome.</p>"},
+		{"blank lines with spaces", "One\n  \t\n\n\nTwo", "<p>One</p><p>Two</p>"},
+		{"line break", "Line one\nline two  ", "<p>Line one<br>line two</p>"},
+		{"surrounding blank lines", "\n\nOnly\n\n", "<p>Only</p>"},
+		{"escaped", "a < b & c", "<p>a &lt; b &amp; c</p>"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ReadText("name", c.text).Page.HtmlContent; got != c.want {
+				t.Errorf("ReadText(%q) = %q, want %q", c.text, got, c.want)
+			}
+		})
+	}
+}
+
+func TestReadFountain(t *testing.T) {
+	cases := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "scene heading",
+			text: "INT. KITCHEN - NIGHT\n\nShe opens the fridge.",
+			want: `<h2 class="scene-heading">INT. KITCHEN - NIGHT</h2><p class="action">She opens the fridge.</p>`,
+		},
+		{
+			name: "forced scene heading with number",
+			text: ".FLASHBACK #12A#",
+			want: `<h2 class="scene-heading" data-scene-number="12A">FLASHBACK</h2>`,
+		},
+		{
+			name: "scene heading needs a blank line before",
+			text: "She waits.\nINT. HALL",
+			want: `<p class="action">She waits.<br>INT. HALL</p>`,
+		},
+		{
+			name: "character and dialogue",
+			text: "ANNA\nGood evening.\nWelcome.",
+			want: `<p class="character"><strong>ANNA</strong></p><p class="dialogue">Good evening.<br>Welcome.</p>`,
+		},
+		{
+			name: "character extension",
+			text: "ANNA (V.O.)\nHello.",
+			want: `<p class="character"><strong>ANNA (V.O.)</strong></p><p class="dialogue">Hello.</p>`,
+		},
+		{
+			name: "forced character",
+			text: "@McCLANE\nYippee.",
+			want: `<p class="character"><strong>McCLANE</strong></p><p class="dialogue">Yippee.</p>`,
+		},
+		{
+			name: "parentheticals",
+			text: "ANNA\n(quietly)\nGood evening.\n(louder)\nWelcome!",
+			want: `<p class="character"><strong>ANNA</strong></p>` +
+				`<p class="parenthetical"><em>(quietly)</em></p>` +
+				`<p class="dialogue">Good evening.</p>` +
+				`<p class="parenthetical"><em>(louder)</em></p>` +
+				`<p class="dialogue">Welcome!</p>`,
+		},
+		{
+			name: "character without dialogue is action",
+			text: "THE END",
+			want: `<p class="action">THE END</p>`,
+		},
+		{
+			name: "dialogue ends at a blank line",
+			text: "BOB\nHi.\n\nHe leaves.",
+			want: `<p class="character"><strong>BOB</strong></p><p class="dialogue">Hi.</p><p class="action">He leaves.</p>`,
+		},
+		{
+			name: "emphasis",
+			text: "She is **very** *quiet* and _alone_.",
+			want: `<p class="action">She is <strong>very</strong> <em>quiet</em> and <u>alone</u>.</p>`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ReadFountain("name", c.text).Page.HtmlContent; got != c.want {
+				t.Errorf("ReadFountain(%q) =\n%s\nwant\n%s", c.text, got, c.want)
+			}
+		})
+	}
+}
+
+func TestReadFountainTitlePage(t *testing.T) {
+	result := ReadFountain("name", "Title: The Visit\nAuthor: Anna\n\nEXT. GARDEN - DAY\n\nRain.")
+
+	if want := "The Visit"; result.Page.Title != want {
+		t.Errorf("title = %q, want %q", result.Page.Title, want)
+	}
+
+	want := `<h1>The Visit</h1><p class="title-page">Anna</p><hr>` +
+		`<h2 class="scene-heading">EXT. GARDEN - DAY</h2><p class="action">Rain.</p>`
+	if result.Page.HtmlContent != want {
+		t.Errorf("content =\n%s\nwant\n%s", result.Page.HtmlContent, want)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package importer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var blankLinesRegex = regexp.MustCompile(`\n[ \t]*\n\s*`)
+
+// ReadText reads plain text as a page, blank lines separate its paragraphs
+func ReadText(name string, text string) *Result {
+	var builder strings.Builder
+
+	for _, paragraph := range blankLinesRegex.Split(strings.TrimSpace(text), -1) {
+		if paragraph == "" {
+			continue
+		}
+
+		lines := strings.Split(paragraph, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(strings.TrimRight(line, " \t"))
+		}
+
+		builder.WriteString("<p>" + strings.Join(lines, "<br>") + "</p>")
+	}
+
+	return newResult("", name, builder.String(), &report{})
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package markdown
+
+import (
+	"archive/zip"
+	"io"
+	"myscript/internal/blocks"
+	"myscript/internal/repository"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Extensions of the files read as Markdown
+var EXTENSIONS = []string{".md", ".markdown"}
+
+var unsafeNameRegex = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]+`)
+
+// IsMarkdownFile tells whether a file name has a Markdown extension
+func IsMarkdownFile(name string) bool {
+	extension := strings.ToLower(filepath.Ext(name))
+	for _, markdownExtension := range EXTENSIONS {
+		if extension == markdownExtension {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPage builds a page from a Markdown document, the name is used when it has no heading
+func NewPage(name string, markdown string) repository.Page {
+	content := ToHTML(markdown)
+
+	title := Title(markdown)
+	if title == "" {
+		title = name
+	}
+
+	return repository.Page{
+		Title:       title,
+		HtmlContent: content,
+		Blocks:      blocks.FromHTML(content),
+	}
+}
+
+// ReadFile reads a Markdown file as a page
+func ReadFile(filePath string) (repository.Page, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return repository.Page{}, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+
+	return NewPage(name, string(data)), nil
+}
+
+// ReadDirectory reads a directory as a folder, with its Markdown files and subdirectories as children.
+// Hidden entries are skipped, as are subdirectories without any Markdown file.
+func ReadDirectory(dirPath string) (repository.Page, bool, error) {
+	folder := repository.Page{Title: filepath.Base(dirPath), IsFolder: true}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return folder, false, err
+	}
+
+	// Subdirectories first, then files, by name
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return strings.ToLower(entries[i].Name()) < strings.ToLower(entries[j].Name())
+	})
+
+	found := false
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		entryPath := filepath.Join(dirPath, entry.Name())
+
+		if entry.IsDir() {
+			child, hasFiles, err := ReadDirectory(entryPath)
+			if err != nil {
+				return folder, false, err
+			}
+			if hasFiles {
+				folder.Children = append(folder.Children, child)
+				found = true
+			}
+			continue
+		}
+
+		if !IsMarkdownFile(entry.Name()) {
+			continue
+		}
+
+		page, err := ReadFile(entryPath)
+		if err != nil {
+			return folder, false, err
+		}
+		folder.Children = append(folder.Children, page)
+		found = true
+	}
+
+	return folder, found, nil
+}
+
+// WriteZip writes a page as a Markdown file in a zip archive.
+// A folder becomes a directory holding its children.
+func WriteZip(w io.Writer, page *repository.Page) error {
+	archive := zip.NewWriter(w)
+
+	if err := writePage(archive, "", page, map[string]bool{}); err != nil {
+		archive.Close()
+		return err
+	}
+
+	return archive.Close()
+}
+
+func writePage(archive *zip.Writer, dir string, page *repository.Page, names map[string]bool) error {
+	if page.IsFolder {
+		folderDir := path.Join(dir, uniqueName(names, fileName(page.Title), "")) + "/"
+		if _, err := archive.Create(folderDir); err != nil {
+			return err
+		}
+
+		children := map[string]bool{}
+		for i := range page.Children {
+			if err := writePage(archive, folderDir, &page.Children[i], children); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	file, err := archive.Create(path.Join(dir, uniqueName(names, fileName(page.Title), ".md")))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(file, FromHTML(page.HtmlContent))
+	return err
+}
+
+// fileName makes a title safe to use as a file name
+func fileName(title string) string {
+	name := strings.Trim(unsafeNameRegex.ReplaceAllString(title, "-"), " .")
+	if name == "" {
+		return "Untitled"
+	}
+	return name
+}
+
+// uniqueName numbers a name already used in the same directory
+func uniqueName(names map[string]bool, name string, extension string) string {
+	candidate := name + extension
+	for i := 2; names[strings.ToLower(candidate)]; i++ {
+		candidate = name + " " + strconv.Itoa(i) + extension
+	}
+	names[strings.ToLower(candidate)] = true
+
+	return candidate
+}
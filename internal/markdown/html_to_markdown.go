@@ -0,0 +1,341 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package markdown
+
+import (
+	"myscript/internal/utils"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	// Characters starting a block at the beginning of a line
+	lineStartRegex   = regexp.MustCompile(`(?m)^([#>+-]|=+$|\d+[.)])`)
+	entityLikeRegex  = regexp.MustCompile(`&(#?[A-Za-z0-9]+;)`)
+	backtickRunRegex = regexp.MustCompile("`+")
+)
+
+// FromHTML converts the HTML content of a page to CommonMark
+func FromHTML(content string) string {
+	return strings.Join(blocksMarkdown(utils.ParseHTMLFragment(content)), "\n\n") + "\n"
+}
+
+// blocksMarkdown converts the children of a block element, inline content is kept as paragraphs
+func blocksMarkdown(parent *html.Node) []string {
+	var blocks []string
+	var inline strings.Builder
+
+	flush := func() {
+		if paragraph := paragraphMarkdown(inline.String()); paragraph != "" {
+			blocks = append(blocks, paragraph)
+		}
+		inline.Reset()
+	}
+
+	for child := parent.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && isBlockElement(child) {
+			flush()
+			if block := blockMarkdown(child); block != "" {
+				blocks = append(blocks, block)
+			}
+			continue
+		}
+
+		inline.WriteString(inlineMarkdown(child))
+	}
+	flush()
+
+	return blocks
+}
+
+func isBlockElement(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Li, atom.Blockquote, atom.Pre, atom.Hr,
+		atom.Div, atom.Section, atom.Article, atom.Header, atom.Footer, atom.Main,
+		atom.Table, atom.Thead, atom.Tbody, atom.Tfoot, atom.Tr, atom.Td, atom.Th,
+		atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd:
+		return true
+	}
+	return false
+}
+
+func blockMarkdown(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.P, atom.Dt, atom.Dd, atom.Td, atom.Th, atom.Figcaption:
+		return paragraphMarkdown(childrenMarkdown(n))
+
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := strings.TrimSpace(strings.ReplaceAll(childrenMarkdown(n), "\\\n", " "))
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", int(n.Data[1]-'0')) + " " + text
+
+	case atom.Ul, atom.Ol:
+		return listMarkdown(n)
+
+	case atom.Li:
+		return itemMarkdown("- ", n)
+
+	case atom.Blockquote:
+		content := strings.Join(blocksMarkdown(n), "\n\n")
+		return prefixLines(content, "> ", ">")
+
+	case atom.Pre:
+		code := strings.TrimSuffix(utils.HTMLTextContent(n), "\n")
+
+		// The fence is longer than any backtick run of the code
+		fence := "```"
+		for _, run := range backtickRunRegex.FindAllString(code, -1) {
+			if len(run) >= len(fence) {
+				fence = strings.Repeat("`", len(run)+1)
+			}
+		}
+
+		return fence + utils.HTMLCodeLanguage(n) + "\n" + code + "\n" + fence
+
+	case atom.Hr:
+		return "---"
+
+	default:
+		return strings.Join(blocksMarkdown(n), "\n\n")
+	}
+}
+
+func listMarkdown(n *html.Node) string {
+	ordered := n.DataAtom == atom.Ol
+	number := 1
+	if start, err := strconv.Atoi(utils.HTMLAttribute(n, "start")); err == nil && ordered {
+		number = start
+	}
+
+	var items []string
+	tight := true
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li {
+			continue
+		}
+
+		marker := "- "
+		if ordered {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+
+		item := itemMarkdown(marker, child)
+		if strings.Contains(item, "\n\n") {
+			tight = false
+		}
+		items = append(items, item)
+	}
+
+	if tight {
+		return strings.Join(items, "\n")
+	}
+	return strings.Join(items, "\n\n")
+}
+
+// itemMarkdown writes the blocks of a list item aligned after its marker
+func itemMarkdown(marker string, n *html.Node) string {
+	blocks := blocksMarkdown(n)
+
+	// Nested lists follow their item tightly
+	content := ""
+	for i, block := range blocks {
+		if i > 0 {
+			if strings.HasPrefix(block, "- ") || startsWithNumber(block) {
+				content += "\n"
+			} else {
+				content += "\n\n"
+			}
+		}
+		content += block
+	}
+
+	return marker + prefixLines(content, strings.Repeat(" ", len(marker)), "")[len(marker):]
+}
+
+func startsWithNumber(block string) bool {
+	dot := strings.IndexAny(block, ".)")
+	if dot <= 0 {
+		return false
+	}
+	_, err := strconv.Atoi(block[:dot])
+	return err == nil
+}
+
+// prefixLines prefixes the lines of a text, blankPrefix is used for its blank lines
+func prefixLines(text, prefix, blankPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = blankPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// paragraphMarkdown trims the lines of a paragraph and escapes what would start a block
+func paragraphMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimLeft(line, " ")
+	}
+
+	text = strings.TrimSpace(strings.Join(lines, "\n"))
+	text = strings.TrimSuffix(text, "\\")
+
+	return lineStartRegex.ReplaceAllStringFunc(text, func(start string) string {
+		// Digits cannot be escaped, the delimiter after them is
+		if last := len(start) - 1; start[last] == '.' || start[last] == ')' {
+			return start[:last] + "\\" + start[last:]
+		}
+		return "\\" + start
+	})
+}
+
+func childrenMarkdown(n *html.Node) string {
+	var builder strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(inlineMarkdown(child))
+	}
+	return builder.String()
+}
+
+func inlineMarkdown(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escapeText(utils.CollapseSpaces(n.Data))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.DataAtom {
+	case atom.Br:
+		return "\\\n"
+
+	case atom.Strong, atom.B:
+		return wrap(childrenMarkdown(n), "**")
+
+	case atom.Em, atom.I:
+		return wrap(childrenMarkdown(n), emphasisDelimiter(n))
+
+	case atom.S, atom.Del, atom.Strike:
+		return wrap(childrenMarkdown(n), "~~")
+
+	case atom.Code, atom.Kbd, atom.Samp:
+		return codeSpan(utils.HTMLTextContent(n))
+
+	case atom.A:
+		text := childrenMarkdown(n)
+		href := utils.HTMLAttribute(n, "href")
+		if href == "" {
+			return text
+		}
+		return "[" + text + "](" + destination(href) + title(utils.HTMLAttribute(n, "title")) + ")"
+
+	case atom.Img:
+		return "![" + escapeText(utils.HTMLAttribute(n, "alt")) + "](" + destination(utils.HTMLAttribute(n, "src")) + title(utils.HTMLAttribute(n, "title")) + ")"
+
+	case atom.Script, atom.Style, atom.Template:
+		return ""
+
+	default:
+		return childrenMarkdown(n)
+	}
+}
+
+// wrap surrounds a text with emphasis delimiters, the spaces around it stay outside
+func wrap(text, delimiter string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+
+	start := text[:strings.Index(text, trimmed)]
+	end := text[len(start)+len(trimmed):]
+
+	return start + delimiter + trimmed + delimiter + end
+}
+
+// emphasisDelimiter returns the delimiter of an emphasis. Directly inside a strong
+// emphasis, *** would be read back as a strong emphasis inside an emphasis.
+func emphasisDelimiter(n *html.Node) string {
+	parent := n.Parent
+	if parent == nil || (parent.DataAtom != atom.Strong && parent.DataAtom != atom.B) {
+		return "*"
+	}
+
+	for sibling := parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling != n && (sibling.Type != html.TextNode || strings.TrimSpace(sibling.Data) != "") {
+			return "*"
+		}
+	}
+
+	return "_"
+}
+
+func codeSpan(code string) string {
+	code = utils.CollapseSpaces(code)
+	if code == "" {
+		return ""
+	}
+
+	longest := 0
+	for _, run := range backtickRunRegex.FindAllString(code, -1) {
+		longest = max(longest, len(run))
+	}
+	fence := strings.Repeat("`", longest+1)
+
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+
+	return fence + code + fence
+}
+
+func destination(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	return url
+}
+
+func title(text string) string {
+	if text == "" {
+		return ""
+	}
+	return ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// escapeText escapes the characters Markdown would interpret
+func escapeText(text string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\\', '`', '*', '_', '[', ']', '<', '~', '|':
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		case '!':
+			builder.WriteByte(c)
+			if i+1 < len(text) && text[i+1] == '[' {
+				builder.WriteByte('\\')
+			}
+		default:
+			builder.WriteByte(c)
+		}
+	}
+
+	return entityLikeRegex.ReplaceAllString(builder.String(), `\&$1`)
+}
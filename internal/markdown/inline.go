@@ -0,0 +1,532 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	uriAutolinkRegex   = regexp.MustCompile(`^<([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*)>`)
+	emailAutolinkRegex = regexp.MustCompile("^<([a-zA-Z0-9.!#$%&'*+/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*)>")
+	entityRegex        = regexp.MustCompile(`^&(?:#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6}|[A-Za-z][A-Za-z0-9]{1,31});`)
+	tagRegex           = regexp.MustCompile(`<[^>]*>`)
+)
+
+// inline is a piece of the inline content: literal text, rendered HTML or a run of delimiters
+type inline struct {
+	text string
+	html string
+
+	delimiter byte // *, _ or ~
+	count     int  // Delimiters left
+	original  int  // Delimiters of the run
+	canOpen   bool
+	canClose  bool
+	openTags  string
+	closeTags string
+}
+
+type bracket struct {
+	node       int  // Index of the [ or ![ node
+	delimiters int  // Delimiters before the bracket
+	source     int  // Position of the text after the bracket
+	image      bool // ![
+	active     bool // Links cannot contain other links
+}
+
+type inlineParser struct {
+	*parser
+
+	text       string
+	nodes      []*inline
+	delimiters []*inline
+	brackets   []*bracket
+	pending    []byte
+}
+
+// renderInline converts the inline content of a block to HTML
+func (p *parser) renderInline(text string) string {
+	s := &inlineParser{parser: p, text: text}
+	s.parse()
+	s.processEmphasis(0)
+
+	return s.render(s.nodes)
+}
+
+func (s *inlineParser) flush() {
+	if len(s.pending) > 0 {
+		s.nodes = append(s.nodes, &inline{text: string(s.pending)})
+		s.pending = s.pending[:0]
+	}
+}
+
+func (s *inlineParser) parse() {
+	t := s.text
+
+	for i := 0; i < len(t); {
+		c := t[i]
+
+		switch c {
+		case '\\':
+			if i+1 < len(t) && t[i+1] == '\n' {
+				s.lineBreak(true)
+				i = skipSpaces(t, i+2)
+				continue
+			}
+			if i+1 < len(t) && isASCIIPunctuation(t[i+1]) {
+				s.pending = append(s.pending, t[i+1])
+				i += 2
+				continue
+			}
+			s.pending = append(s.pending, c)
+			i++
+
+		case '`':
+			run := runLength(t, i, '`')
+			end := findBackticks(t, i+run, run)
+			if end < 0 {
+				s.pending = append(s.pending, t[i:i+run]...)
+				i += run
+				continue
+			}
+
+			code := strings.ReplaceAll(t[i+run:end], "\n", " ")
+			if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.Trim(code, " ") != "" {
+				code = code[1 : len(code)-1]
+			}
+
+			s.flush()
+			s.nodes = append(s.nodes, &inline{html: "<code>" + html.EscapeString(code) + "</code>"})
+			i = end + run
+
+		case '<':
+			if match := uriAutolinkRegex.FindStringSubmatch(t[i:]); match != nil {
+				s.flush()
+				s.nodes = append(s.nodes, &inline{html: link(match[1], "", html.EscapeString(match[1]))})
+				i += len(match[0])
+				continue
+			}
+			if match := emailAutolinkRegex.FindStringSubmatch(t[i:]); match != nil {
+				s.flush()
+				s.nodes = append(s.nodes, &inline{html: link("mailto:"+match[1], "", html.EscapeString(match[1]))})
+				i += len(match[0])
+				continue
+			}
+			s.pending = append(s.pending, c)
+			i++
+
+		case '&':
+			if match := entityRegex.FindString(t[i:]); match != "" {
+				s.pending = append(s.pending, html.UnescapeString(match)...)
+				i += len(match)
+				continue
+			}
+			s.pending = append(s.pending, c)
+			i++
+
+		case '!', '[':
+			image := c == '!'
+			if image && (i+1 >= len(t) || t[i+1] != '[') {
+				s.pending = append(s.pending, c)
+				i++
+				continue
+			}
+
+			s.flush()
+			node := &inline{text: "["}
+			if image {
+				node.text = "!["
+			}
+			s.nodes = append(s.nodes, node)
+			i += len(node.text)
+
+			s.brackets = append(s.brackets, &bracket{
+				node:       len(s.nodes) - 1,
+				delimiters: len(s.delimiters),
+				source:     i,
+				image:      image,
+				active:     true,
+			})
+
+		case ']':
+			s.flush()
+			i = s.closeBracket(i)
+
+		case '*', '_', '~':
+			run := runLength(t, i, c)
+
+			// Strikethrough takes two tildes
+			if c == '~' && run != 2 {
+				s.pending = append(s.pending, t[i:i+run]...)
+				i += run
+				continue
+			}
+
+			before, after := ' ', ' '
+			if i > 0 {
+				before, _ = utf8.DecodeLastRuneInString(t[:i])
+			}
+			if i+run < len(t) {
+				after, _ = utf8.DecodeRuneInString(t[i+run:])
+			}
+
+			leftFlanking := !unicode.IsSpace(after) &&
+				(!isPunctuation(after) || unicode.IsSpace(before) || isPunctuation(before))
+			rightFlanking := !unicode.IsSpace(before) &&
+				(!isPunctuation(before) || unicode.IsSpace(after) || isPunctuation(after))
+
+			node := &inline{delimiter: c, count: run, original: run, canOpen: leftFlanking, canClose: rightFlanking}
+			if c == '_' {
+				node.canOpen = leftFlanking && (!rightFlanking || isPunctuation(before))
+				node.canClose = rightFlanking && (!leftFlanking || isPunctuation(after))
+			}
+
+			s.flush()
+			s.nodes = append(s.nodes, node)
+			if node.canOpen || node.canClose {
+				s.delimiters = append(s.delimiters, node)
+			}
+			i += run
+
+		case '\n':
+			spaces := len(s.pending) - len(strings.TrimRight(string(s.pending), " "))
+			s.lineBreak(spaces >= 2)
+			i = skipSpaces(t, i+1)
+
+		default:
+			s.pending = append(s.pending, c)
+			i++
+		}
+	}
+
+	s.flush()
+}
+
+// lineBreak ends a line, the spaces before it are dropped
+func (s *inlineParser) lineBreak(hard bool) {
+	s.pending = []byte(strings.TrimRight(string(s.pending), " "))
+
+	if !hard {
+		s.pending = append(s.pending, '\n')
+		return
+	}
+
+	s.flush()
+	s.nodes = append(s.nodes, &inline{html: "<br>\n"})
+}
+
+// closeBracket turns the text since the last opening bracket into a link or an image,
+// and returns the position after it
+func (s *inlineParser) closeBracket(i int) int {
+	if len(s.brackets) == 0 {
+		s.nodes = append(s.nodes, &inline{text: "]"})
+		return i + 1
+	}
+
+	opener := s.brackets[len(s.brackets)-1]
+	s.brackets = s.brackets[:len(s.brackets)-1]
+
+	if !opener.active {
+		s.nodes = append(s.nodes, &inline{text: "]"})
+		return i + 1
+	}
+
+	destination, title, end, ok := parseLinkTail(s.text, i+1)
+	if !ok {
+		// Reference links: [text][label], [label][] or [label]
+		label := s.text[opener.source:i]
+		end = i + 1
+
+		if end < len(s.text) && s.text[end] == '[' {
+			if closing := strings.IndexByte(s.text[end:], ']'); closing > 0 {
+				if full := s.text[end+1 : end+closing]; strings.TrimSpace(full) != "" {
+					label = full
+				}
+				end += closing + 1
+			}
+		}
+
+		var reference linkReference
+		reference, ok = s.references[normalizeLabel(label)]
+		destination, title = reference.destination, reference.title
+	}
+
+	if !ok {
+		s.nodes = append(s.nodes, &inline{text: "]"})
+		return i + 1
+	}
+
+	s.processEmphasis(opener.delimiters)
+
+	if opener.image {
+		alt := html.UnescapeString(tagRegex.ReplaceAllString(s.render(s.nodes[opener.node+1:]), ""))
+
+		image := `<img src="` + html.EscapeString(safeURL(destination, true)) + `" alt="` + html.EscapeString(alt) + `"`
+		if title != "" {
+			image += ` title="` + html.EscapeString(title) + `"`
+		}
+
+		s.nodes = append(s.nodes[:opener.node], &inline{html: image + ">"})
+		return end
+	}
+
+	s.nodes[opener.node] = &inline{html: strings.TrimSuffix(link(destination, title, ""), "</a>")}
+	s.nodes = append(s.nodes, &inline{html: "</a>"})
+
+	for _, previous := range s.brackets {
+		if !previous.image {
+			previous.active = false
+		}
+	}
+
+	return end
+}
+
+// processEmphasis matches the delimiter runs above the given one, as described by CommonMark
+func (s *inlineParser) processEmphasis(bottom int) {
+	openersBottom := map[[3]int]int{}
+
+	for closerIndex := bottom; closerIndex < len(s.delimiters); {
+		closer := s.delimiters[closerIndex]
+		if !closer.canClose {
+			closerIndex++
+			continue
+		}
+
+		canOpen := 0
+		if closer.canOpen {
+			canOpen = 1
+		}
+		key := [3]int{int(closer.delimiter), closer.original % 3, canOpen}
+
+		openerIndex := -1
+		for i := closerIndex - 1; i >= max(bottom, openersBottom[key]); i-- {
+			opener := s.delimiters[i]
+			if opener.delimiter != closer.delimiter || !opener.canOpen {
+				continue
+			}
+			if (opener.canClose || closer.canOpen) && (opener.original+closer.original)%3 == 0 &&
+				!(opener.original%3 == 0 && closer.original%3 == 0) {
+				continue
+			}
+
+			openerIndex = i
+			break
+		}
+
+		if openerIndex < 0 {
+			openersBottom[key] = closerIndex
+			if !closer.canOpen {
+				s.delimiters = append(s.delimiters[:closerIndex], s.delimiters[closerIndex+1:]...)
+			} else {
+				closerIndex++
+			}
+			continue
+		}
+
+		opener := s.delimiters[openerIndex]
+
+		used, tag := 1, "em"
+		if opener.count >= 2 && closer.count >= 2 {
+			used, tag = 2, "strong"
+		}
+		if closer.delimiter == '~' {
+			tag = "s"
+		}
+
+		opener.count -= used
+		closer.count -= used
+		opener.openTags = "<" + tag + ">" + opener.openTags
+		closer.closeTags += "</" + tag + ">"
+
+		// Delimiters between them can no longer match
+		s.delimiters = append(s.delimiters[:openerIndex+1], s.delimiters[closerIndex:]...)
+		closerIndex = openerIndex + 1
+
+		if opener.count == 0 {
+			s.delimiters = append(s.delimiters[:openerIndex], s.delimiters[openerIndex+1:]...)
+			closerIndex--
+		}
+		if closer.count == 0 {
+			s.delimiters = append(s.delimiters[:closerIndex], s.delimiters[closerIndex+1:]...)
+		}
+	}
+
+	s.delimiters = s.delimiters[:bottom]
+}
+
+func (s *inlineParser) render(nodes []*inline) string {
+	var builder strings.Builder
+
+	for _, node := range nodes {
+		switch {
+		case node.delimiter != 0:
+			builder.WriteString(node.closeTags)
+			builder.WriteString(strings.Repeat(string(node.delimiter), node.count))
+			builder.WriteString(node.openTags)
+		case node.html != "":
+			builder.WriteString(node.html)
+		default:
+			builder.WriteString(html.EscapeString(node.text))
+		}
+	}
+
+	return builder.String()
+}
+
+// parseLinkTail reads the (destination "title") following the text of an inline link
+func parseLinkTail(t string, start int) (destination, title string, end int, ok bool) {
+	if start >= len(t) || t[start] != '(' {
+		return "", "", 0, false
+	}
+
+	i := skipWhitespace(t, start+1)
+
+	if i < len(t) && t[i] == '<' {
+		closing := strings.IndexAny(t[i+1:], "<>\n")
+		if closing < 0 || t[i+1+closing] != '>' {
+			return "", "", 0, false
+		}
+		destination = t[i+1 : i+1+closing]
+		i += closing + 2
+	} else {
+		depth, j := 0, i
+		for ; j < len(t); j++ {
+			c := t[j]
+			if c == '\\' && j+1 < len(t) && isASCIIPunctuation(t[j+1]) {
+				j++
+				continue
+			}
+			if c == '(' {
+				depth++
+			} else if c == ')' {
+				if depth == 0 {
+					break
+				}
+				depth--
+			} else if c <= ' ' {
+				break
+			}
+		}
+		destination = t[i:j]
+		i = j
+	}
+
+	if next := skipWhitespace(t, i); next > i && next < len(t) && strings.IndexByte(`"'(`, t[next]) >= 0 {
+		closer := t[next]
+		if closer == '(' {
+			closer = ')'
+		}
+
+		j := next + 1
+		for ; j < len(t) && t[j] != closer; j++ {
+			if t[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(t) {
+			return "", "", 0, false
+		}
+
+		title = unescapeBackslashes(html.UnescapeString(t[next+1 : j]))
+		i = j + 1
+	}
+
+	i = skipWhitespace(t, i)
+	if i >= len(t) || t[i] != ')' {
+		return "", "", 0, false
+	}
+
+	return unescapeBackslashes(html.UnescapeString(destination)), title, i + 1, true
+}
+
+func link(destination, title, content string) string {
+	a := `<a href="` + html.EscapeString(safeURL(destination, false)) + `"`
+	if title != "" {
+		a += ` title="` + html.EscapeString(title) + `"`
+	}
+
+	return a + ">" + content + "</a>"
+}
+
+// safeURL drops the URLs running scripts, pages are rendered as is
+func safeURL(url string, image bool) string {
+	scheme := strings.ToLower(strings.TrimSpace(url))
+
+	if strings.HasPrefix(scheme, "javascript:") || strings.HasPrefix(scheme, "vbscript:") ||
+		(strings.HasPrefix(scheme, "data:") && !(image && strings.HasPrefix(scheme, "data:image/"))) {
+		return ""
+	}
+
+	return url
+}
+
+func unescapeBackslashes(text string) string {
+	if !strings.Contains(text, "\\") {
+		return text
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) && isASCIIPunctuation(text[i+1]) {
+			i++
+		}
+		builder.WriteByte(text[i])
+	}
+
+	return builder.String()
+}
+
+func runLength(t string, i int, c byte) int {
+	n := 0
+	for i+n < len(t) && t[i+n] == c {
+		n++
+	}
+	return n
+}
+
+// findBackticks returns the position of the next run of exactly n backticks
+func findBackticks(t string, from, n int) int {
+	for i := from; i < len(t); {
+		if t[i] != '`' {
+			i++
+			continue
+		}
+
+		run := runLength(t, i, '`')
+		if run == n {
+			return i
+		}
+		i += run
+	}
+
+	return -1
+}
+
+func skipSpaces(t string, i int) int {
+	for i < len(t) && t[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+func skipWhitespace(t string, i int) int {
+	for i < len(t) && (t[i] == ' ' || t[i] == '\t' || t[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+func isASCIIPunctuation(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunctuation(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,469 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+// Package markdown converts CommonMark to the HTML content of pages and back.
+// Raw HTML in Markdown is escaped, as page content is rendered as is.
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	blockParagraph = iota
+	blockHeading
+	blockCode
+	blockRule
+	blockQuote
+	blockList
+	blockItem
+)
+
+type block struct {
+	kind     int
+	level    int    // Of the headings
+	text     string // Inline content of the paragraphs and headings, or code
+	language string
+	ordered  bool
+	start    int
+	children []*block
+}
+
+type linkReference struct {
+	destination string
+	title       string
+}
+
+var (
+	atxHeadingRegex    = regexp.MustCompile(`^#{1,6}(?:[ \t]+|$)`)
+	atxClosingRegex    = regexp.MustCompile(`(?:^|[ \t]+)#+[ \t]*$`)
+	ruleRegex          = regexp.MustCompile(`^(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	fenceRegex         = regexp.MustCompile("^(`{3,}|~{3,})[ \t]*(.*)$")
+	listItemRegex      = regexp.MustCompile(`^([-+*]|(\d{1,9})([.)]))(?:([ \t]+)(.*)|$)`)
+	setextRegex        = regexp.MustCompile(`^(=+|-+)[ \t]*$`)
+	linkReferenceRegex = regexp.MustCompile(`^\[((?:[^\\\[\]]|\\.){1,999})\]:[ \t]*\n?[ \t]*(<[^<>\n]*>|\S+)(?:[ \t]*\n?[ \t]*("(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'|\((?:[^()\\]|\\.)*\)))?[ \t]*(?:\n|$)`)
+)
+
+type parser struct {
+	references map[string]linkReference
+}
+
+// ToHTML converts a CommonMark document to HTML
+func ToHTML(markdown string) string {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	markdown = strings.ReplaceAll(markdown, "\r", "\n")
+	markdown = strings.ReplaceAll(markdown, "\x00", "\ufffd")
+
+	p := &parser{references: map[string]linkReference{}}
+	blocks := p.parseBlocks(strings.Split(markdown, "\n"))
+
+	var builder strings.Builder
+	p.renderBlocks(&builder, blocks)
+
+	return builder.String()
+}
+
+// Title returns the text of the first heading of a Markdown document
+func Title(markdown string) string {
+	p := &parser{references: map[string]linkReference{}}
+
+	for _, block := range p.parseBlocks(strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")) {
+		if block.kind == blockHeading {
+			return strings.TrimSpace(html.UnescapeString(tagRegex.ReplaceAllString(p.renderInline(block.text), "")))
+		}
+	}
+
+	return ""
+}
+
+// indentation returns the width of the leading whitespace, with tab stops of 4 columns
+func indentation(line string) int {
+	column := 0
+
+	for _, r := range line {
+		switch r {
+		case '\t':
+			column += 4 - column%4
+		case ' ':
+			column++
+		default:
+			return column
+		}
+	}
+
+	return column
+}
+
+// stripIndentation removes up to the given number of columns of leading whitespace.
+// A tab only partly removed leaves its remaining columns as spaces, the tabs after it
+// are kept, so code keeps its tabs.
+func stripIndentation(line string, columns int) string {
+	column := 0
+
+	for i, r := range line {
+		if column >= columns {
+			return line[i:]
+		}
+
+		switch r {
+		case '\t':
+			next := column + 4 - column%4
+			if next > columns {
+				return strings.Repeat(" ", next-columns) + line[i+1:]
+			}
+			column = next
+		case ' ':
+			column++
+		default:
+			return line[i:]
+		}
+	}
+
+	return ""
+}
+
+func trimIndentation(line string) string {
+	return strings.TrimLeft(line, " \t")
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// interruptsParagraph tells whether a line starts a block that ends a paragraph
+func interruptsParagraph(line string) bool {
+	if indentation(line) >= 4 {
+		return false
+	}
+
+	trimmed := trimIndentation(line)
+	if atxHeadingRegex.MatchString(trimmed) || ruleRegex.MatchString(trimmed) ||
+		fenceRegex.MatchString(trimmed) || strings.HasPrefix(trimmed, ">") {
+		return true
+	}
+
+	// Only non-empty lists starting at 1 interrupt a paragraph
+	if match := listItemRegex.FindStringSubmatch(trimmed); match != nil && strings.TrimSpace(match[5]) != "" {
+		return match[2] == "" || match[2] == "1"
+	}
+
+	return false
+}
+
+func (p *parser) parseBlocks(lines []string) []*block {
+	var blocks []*block
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		if isBlank(line) {
+			i++
+			continue
+		}
+
+		indent := indentation(line)
+		if indent >= 4 {
+			var code []string
+			for ; i < len(lines) && (isBlank(lines[i]) || indentation(lines[i]) >= 4); i++ {
+				code = append(code, stripIndentation(lines[i], 4))
+			}
+			for len(code) > 0 && isBlank(code[len(code)-1]) {
+				code = code[:len(code)-1]
+			}
+
+			blocks = append(blocks, &block{kind: blockCode, text: strings.Join(code, "\n") + "\n"})
+			continue
+		}
+
+		trimmed := trimIndentation(line)
+
+		if match := fenceRegex.FindStringSubmatch(trimmed); match != nil &&
+			!(match[1][0] == '`' && strings.Contains(match[2], "`")) {
+			fence := match[1]
+			code := &block{kind: blockCode}
+			if info := strings.Fields(match[2]); len(info) > 0 {
+				code.language = unescapeBackslashes(html.UnescapeString(info[0]))
+			}
+
+			var content []string
+			for i++; i < len(lines); i++ {
+				closing := strings.TrimSpace(lines[i])
+				if indentation(lines[i]) < 4 && strings.HasPrefix(closing, fence) &&
+					strings.Trim(closing, fence[:1]) == "" {
+					i++
+					break
+				}
+
+				// The content loses the indentation of the fence
+				content = append(content, stripIndentation(lines[i], indent))
+			}
+
+			if len(content) > 0 {
+				code.text = strings.Join(content, "\n") + "\n"
+			}
+			blocks = append(blocks, code)
+			continue
+		}
+
+		if atxHeadingRegex.MatchString(trimmed) {
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			text := strings.TrimSpace(trimmed[level:])
+			text = strings.TrimSpace(atxClosingRegex.ReplaceAllString(text, ""))
+
+			blocks = append(blocks, &block{kind: blockHeading, level: level, text: text})
+			i++
+			continue
+		}
+
+		if ruleRegex.MatchString(trimmed) {
+			blocks = append(blocks, &block{kind: blockRule})
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			var quoted []string
+			lazy := false
+
+			for ; i < len(lines); i++ {
+				current := trimIndentation(lines[i])
+
+				if indentation(lines[i]) < 4 && strings.HasPrefix(current, ">") {
+					current = stripIndentation(current[1:], 1)
+					quoted = append(quoted, current)
+					lazy = !isBlank(current)
+					continue
+				}
+
+				// Paragraph continuation lines may omit the marker
+				if lazy && !isBlank(lines[i]) && !interruptsParagraph(lines[i]) {
+					quoted = append(quoted, lines[i])
+					continue
+				}
+
+				break
+			}
+
+			blocks = append(blocks, &block{kind: blockQuote, children: p.parseBlocks(quoted)})
+			continue
+		}
+
+		if match := listItemRegex.FindStringSubmatch(trimmed); match != nil {
+			list, next := p.parseList(lines, i)
+			blocks = append(blocks, list)
+			i = next
+			continue
+		}
+
+		// Paragraph, or setext heading
+		var paragraph []string
+		heading := 0
+
+		for ; i < len(lines); i++ {
+			current := lines[i]
+			if isBlank(current) {
+				break
+			}
+
+			if len(paragraph) > 0 && indentation(current) < 4 {
+				if match := setextRegex.FindStringSubmatch(strings.TrimSpace(current)); match != nil {
+					heading = 2
+					if match[1][0] == '=' {
+						heading = 1
+					}
+					i++
+					break
+				}
+
+				if interruptsParagraph(current) {
+					break
+				}
+			}
+
+			paragraph = append(paragraph, trimIndentation(current))
+		}
+
+		text := p.extractReferences(strings.Join(paragraph, "\n"))
+		if text == "" {
+			continue
+		}
+
+		if heading > 0 {
+			blocks = append(blocks, &block{kind: blockHeading, level: heading, text: strings.TrimSpace(text)})
+		} else {
+			blocks = append(blocks, &block{kind: blockParagraph, text: strings.TrimRight(text, " ")})
+		}
+	}
+
+	return blocks
+}
+
+// parseList reads the items of a list starting at the given line, and returns the line after it
+func (p *parser) parseList(lines []string, i int) (*block, int) {
+	list := &block{kind: blockList}
+	marker := ""
+
+	for i < len(lines) {
+		line := lines[i]
+		indent := indentation(line)
+		if indent >= 4 {
+			break
+		}
+
+		trimmed := trimIndentation(line)
+		match := listItemRegex.FindStringSubmatch(trimmed)
+		if match == nil || ruleRegex.MatchString(trimmed) {
+			break
+		}
+
+		// Bullets and delimiters of the items of a list are the same
+		itemMarker := match[1]
+		if match[2] != "" {
+			itemMarker = match[3]
+		}
+
+		if marker == "" {
+			marker = itemMarker
+			list.ordered = match[2] != ""
+			if list.ordered {
+				list.start, _ = strconv.Atoi(match[2])
+			}
+		} else if itemMarker != marker {
+			break
+		}
+
+		// The content is aligned after the marker, a long gap means indented code
+		gap := indentation(match[4])
+		spaces := gap
+		if spaces > 4 || match[5] == "" {
+			spaces = 1
+		}
+		contentIndent := indent + len(match[1]) + spaces
+
+		first := ""
+		if match[4] != "" {
+			first = strings.Repeat(" ", gap-spaces) + match[5]
+		}
+
+		itemLines := []string{first}
+		lazy := !isBlank(first)
+
+		for i++; i < len(lines); i++ {
+			current := lines[i]
+
+			if isBlank(current) {
+				itemLines = append(itemLines, "")
+				lazy = false
+				continue
+			}
+
+			if indentation(current) >= contentIndent {
+				itemLines = append(itemLines, stripIndentation(current, contentIndent))
+				lazy = true
+				continue
+			}
+
+			if lazy && !interruptsParagraph(current) && listItemRegex.FindString(trimIndentation(current)) == "" {
+				itemLines = append(itemLines, current)
+				continue
+			}
+
+			break
+		}
+
+		list.children = append(list.children, &block{kind: blockItem, children: p.parseBlocks(itemLines)})
+	}
+
+	return list, i
+}
+
+// extractReferences registers the link reference definitions starting a paragraph,
+// and returns the rest of it
+func (p *parser) extractReferences(text string) string {
+	for {
+		match := linkReferenceRegex.FindStringSubmatch(text)
+		if match == nil {
+			return text
+		}
+
+		label := normalizeLabel(match[1])
+		if label == "" {
+			return text
+		}
+
+		destination := strings.TrimSuffix(strings.TrimPrefix(match[2], "<"), ">")
+		title := ""
+		if match[3] != "" {
+			title = unescapeBackslashes(match[3][1 : len(match[3])-1])
+		}
+
+		// The first definition wins
+		if _, ok := p.references[label]; !ok {
+			p.references[label] = linkReference{destination: unescapeBackslashes(destination), title: title}
+		}
+
+		text = text[len(match[0]):]
+	}
+}
+
+func normalizeLabel(label string) string {
+	return strings.ToLower(strings.Join(strings.Fields(label), " "))
+}
+
+func (p *parser) renderBlocks(builder *strings.Builder, blocks []*block) {
+	for _, block := range blocks {
+		switch block.kind {
+		case blockParagraph:
+			builder.WriteString("<p>" + p.renderInline(block.text) + "</p>")
+
+		case blockHeading:
+			tag := "h" + strconv.Itoa(block.level)
+			builder.WriteString("<" + tag + ">" + p.renderInline(block.text) + "</" + tag + ">")
+
+		case blockCode:
+			builder.WriteString("<pre><code")
+			if block.language != "" {
+				builder.WriteString(` class="language-` + html.EscapeString(block.language) + `"`)
+			}
+			builder.WriteString(">" + html.EscapeString(block.text) + "</code></pre>")
+
+		case blockRule:
+			builder.WriteString("<hr>")
+
+		case blockQuote:
+			builder.WriteString("<blockquote>")
+			p.renderBlocks(builder, block.children)
+			builder.WriteString("</blockquote>")
+
+		case blockList:
+			if block.ordered {
+				if block.start != 1 {
+					builder.WriteString(`<ol start="` + strconv.Itoa(block.start) + `">`)
+				} else {
+					builder.WriteString("<ol>")
+				}
+			} else {
+				builder.WriteString("<ul>")
+			}
+
+			for _, item := range block.children {
+				// Items always hold paragraphs, as in the editor
+				builder.WriteString("<li>")
+				p.renderBlocks(builder, item.children)
+				if len(item.children) == 0 {
+					builder.WriteString("<p></p>")
+				}
+				builder.WriteString("</li>")
+			}
+
+			if block.ordered {
+				builder.WriteString("</ol>")
+			} else {
+				builder.WriteString("</ul>")
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package markdown
+
+import (
+	"myscript/internal/blocks"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "headings",
+			content: "<h1>Opening</h1><p>Good evening.</p><h2>First part</h2><h3>Details</h3><p>Welcome.</p>",
+		},
+		{
+			name:    "nested lists",
+			content: "<ul><li><p>One</p><ul><li><p>Nested</p></li></ul></li><li><p>Two</p></li></ul><ol><li><p>First</p><ol><li><p>Inner</p></li></ol></li><li><p>Second</p></li></ol>",
+		},
+		{
+			name:    "code block",
+			content: "<pre><code>func main() {\n\tfmt.Println(\"*hello*\")\n}</code></pre>",
+		},
+		{
+			name:    "code block in a list",
+			content: "<ol><li><p>Step</p><pre><code>if ready {\n\treturn\n}</code></pre></li></ol>",
+		},
+		{
+			name:    "inline code",
+			content: "<p>Run <code>go test</code> and <code>a `quoted` b</code>.</p>",
+		},
+		{
+			name:    "links",
+			content: `<p>See <a href="https://example.com/a_(b)">the site</a> and <a href="https://example.com" title="Home">home</a>.</p>`,
+		},
+		{
+			name:    "emphasis",
+			content: "<p><strong>bold</strong>, <em>italic</em>, <s>struck</s> and <strong><em>both</em></strong>.</p>",
+		},
+		{
+			name:    "emphasis inside words",
+			content: "<p>un<strong>believ</strong>able and <em>half</em>way</p>",
+		},
+		{
+			name:    "escaped text",
+			content: "<p>2 * 3 = 6, not _this_ or [that] # here</p>",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			exported := FromHTML(c.content)
+			imported := NewPage("page", exported)
+
+			if got, want := string(imported.Blocks), string(blocks.FromHTML(c.content)); got != want {
+				t.Errorf("blocks after the round trip through\n%s\n= %s\nwant %s", exported, got, want)
+			}
+		})
+	}
+}
+
+func TestToHTMLTabs(t *testing.T) {
+	cases := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"indented code", "\tcode\there\n", "<pre><code>code\there\n</code></pre>"},
+		{"fenced code", "```\n\tkeep\n```\n", "<pre><code>\tkeep\n</code></pre>"},
+		{"indented fence", "  ```\n\t\tkeep\n  ```\n", "<pre><code>  \tkeep\n</code></pre>"},
+		{"list item continuation", "- one\n\n\tmore\n", "<ul><li><p>one</p><p>more</p></li></ul>"},
+		{"list marker", "-\tone\n-\ttwo\n", "<ul><li><p>one</p></li><li><p>two</p></li></ul>"},
+		{"quote marker", ">\tquoted\n", "<blockquote><p>quoted</p></blockquote>"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ToHTML(c.markdown); got != c.want {
+				t.Errorf("ToHTML(%q) = %q, want %q", c.markdown, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package normalize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type currency struct {
+	singular      string
+	plural        string
+	minorSingular string // Empty when the minor unit is not read
+	minorPlural   string
+}
+
+type language struct {
+	cardinal     func(int64) string
+	nounCardinal func(int64) string // Form used before a noun, nil when it is the cardinal
+	ordinal      func(int64) string
+	year         func(int64) string // Nil when years are read as cardinals
+
+	decimalSeparator byte
+	decimalWord      string
+	digitByDigit     bool // Decimals are read digit by digit, else as a number
+
+	monthFirst bool // Numeric dates are written month first
+	months     []string
+	date       func(lang *language, day, month, year int64) string
+	time       func(lang *language, hours, minutes int64) string
+
+	percent       string
+	currencies    map[string]currency
+	ordinalRegex  *regexp.Regexp // Digits followed by an ordinal suffix, e.g. 1st
+	abbreviations map[string][]string
+}
+
+// Abbreviations are matched lowercased, without their final dot. Keys ending with a dot
+// only match when the abbreviation is followed by a dot, e.g. "no." but not "no".
+
+var languages = map[string]*language{
+	"en": {
+		cardinal:         englishCardinal,
+		ordinal:          englishOrdinal,
+		year:             englishYear,
+		decimalSeparator: '.',
+		decimalWord:      "point",
+		digitByDigit:     true,
+		monthFirst:       true,
+		months: []string{
+			"january", "february", "march", "april", "may", "june",
+			"july", "august", "september", "october", "november", "december",
+		},
+		date: func(lang *language, day, month, year int64) string {
+			return joinWords(lang.months[month-1], lang.ordinal(day), lang.year(year))
+		},
+		time: func(lang *language, hours, minutes int64) string {
+			switch {
+			case minutes == 0:
+				return joinWords(lang.cardinal(hours), "o'clock")
+			case minutes < 10:
+				return joinWords(lang.cardinal(hours), "oh", lang.cardinal(minutes))
+			default:
+				return joinWords(lang.cardinal(hours), lang.cardinal(minutes))
+			}
+		},
+		percent: "percent",
+		currencies: map[string]currency{
+			"$": {"dollar", "dollars", "cent", "cents"},
+			"€": {"euro", "euros", "cent", "cents"},
+			"£": {"pound", "pounds", "penny", "pence"},
+			"¥": {"yen", "yen", "", ""},
+		},
+		ordinalRegex: regexp.MustCompile(`^(\d+)(?i:st|nd|rd|th)$`),
+		abbreviations: map[string][]string{
+			"dr":     {"doctor"},
+			"mr":     {"mister"},
+			"mrs":    {"missus"},
+			"prof":   {"professor"},
+			"jr":     {"junior"},
+			"sr":     {"senior"},
+			"st.":    {"saint"},
+			"mt":     {"mount"},
+			"vs":     {"versus"},
+			"etc":    {"et", "cetera"},
+			"e.g":    {"for", "example"},
+			"i.e":    {"that", "is"},
+			"approx": {"approximately"},
+			"dept":   {"department"},
+		},
+	},
+	"fr": {
+		cardinal:         frenchCardinal,
+		ordinal:          frenchOrdinal,
+		decimalSeparator: ',',
+		decimalWord:      "virgule",
+		months: []string{
+			"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+		},
+		date: func(lang *language, day, month, year int64) string {
+			dayWords := lang.cardinal(day)
+			if day == 1 {
+				dayWords = lang.ordinal(day)
+			}
+			return joinWords(dayWords, lang.months[month-1], lang.cardinal(year))
+		},
+		time: func(lang *language, hours, minutes int64) string {
+			hoursWord := "heures"
+			if hours <= 1 {
+				hoursWord = "heure"
+			}
+			return joinWords(lang.cardinal(hours), hoursWord, nonZero(minutes, lang.cardinal))
+		},
+		percent: "pour cent",
+		currencies: map[string]currency{
+			"$": {"dollar", "dollars", "cent", "cents"},
+			"€": {"euro", "euros", "centime", "centimes"},
+			"£": {"livre", "livres", "penny", "pence"},
+			"¥": {"yen", "yens", "", ""},
+		},
+		ordinalRegex: regexp.MustCompile(`^(\d+)(?:er|re|e|ème|eme|ᵉ)$`),
+		abbreviations: map[string][]string{
+			"m.":     {"monsieur"},
+			"mm.":    {"messieurs"},
+			"mme":    {"madame"},
+			"mmes":   {"mesdames"},
+			"mlle":   {"mademoiselle"},
+			"dr":     {"docteur"},
+			"pr":     {"professeur"},
+			"st":     {"saint"},
+			"ste":    {"sainte"},
+			"etc":    {"et", "cetera"},
+			"c.-à-d": {"c'est", "à", "dire"},
+			"cf":     {"confer"},
+		},
+	},
+	"es": {
+		cardinal:         spanishCardinal,
+		nounCardinal:     spanishNounCardinal,
+		ordinal:          spanishOrdinal,
+		decimalSeparator: ',',
+		decimalWord:      "coma",
+		months: []string{
+			"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+		},
+		date: func(lang *language, day, month, year int64) string {
+			return joinWords(lang.cardinal(day), "de", lang.months[month-1], "de", lang.cardinal(year))
+		},
+		time: func(lang *language, hours, minutes int64) string {
+			if minutes == 0 {
+				return lang.cardinal(hours)
+			}
+			return joinWords(lang.cardinal(hours), "y", lang.cardinal(minutes))
+		},
+		percent: "por ciento",
+		currencies: map[string]currency{
+			"$": {"dólar", "dólares", "centavo", "centavos"},
+			"€": {"euro", "euros", "céntimo", "céntimos"},
+			"£": {"libra", "libras", "penique", "peniques"},
+			"¥": {"yen", "yenes", "", ""},
+		},
+		ordinalRegex: regexp.MustCompile(`^(\d+)[ºª°]$`),
+		abbreviations: map[string][]string{
+			"sr":   {"señor"},
+			"sra":  {"señora"},
+			"srta": {"señorita"},
+			"dr":   {"doctor"},
+			"dra":  {"doctora"},
+			"ud":   {"usted"},
+			"uds":  {"ustedes"},
+			"etc":  {"etcétera"},
+			"p.ej": {"por", "ejemplo"},
+		},
+	},
+	"de": {
+		cardinal:         germanCardinal,
+		ordinal:          germanOrdinal,
+		year:             germanYear,
+		decimalSeparator: ',',
+		decimalWord:      "komma",
+		months: []string{
+			"januar", "februar", "märz", "april", "mai", "juni",
+			"juli", "august", "september", "oktober", "november", "dezember",
+		},
+		date: func(lang *language, day, month, year int64) string {
+			return joinWords(lang.ordinal(day), lang.months[month-1], lang.year(year))
+		},
+		time: func(lang *language, hours, minutes int64) string {
+			hoursWords := lang.cardinal(hours)
+			if hours == 1 {
+				hoursWords = "ein"
+			}
+			return joinWords(hoursWords, "uhr", nonZero(minutes, lang.cardinal))
+		},
+		percent: "prozent",
+		currencies: map[string]currency{
+			"$": {"dollar", "dollar", "cent", "cent"},
+			"€": {"euro", "euro", "cent", "cent"},
+			"£": {"pfund", "pfund", "penny", "pence"},
+			"¥": {"yen", "yen", "", ""},
+		},
+		abbreviations: map[string][]string{
+			"dr":   {"doktor"},
+			"prof": {"professor"},
+			"hr.":  {"herr"},
+			"fr.":  {"frau"},
+			"nr":   {"nummer"},
+			"ca":   {"circa"},
+			"etc":  {"et", "cetera"},
+			"z.b":  {"zum", "beispiel"},
+			"d.h":  {"das", "heißt"},
+			"usw":  {"und", "so", "weiter"},
+			"bzw":  {"beziehungsweise"},
+		},
+	},
+}
+
+// getLanguage returns the rules of a language code such as "en" or "fr-CA",
+// or nil when the language is not supported
+func getLanguage(code string) *language {
+	code = strings.ToLower(code)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+
+	return languages[code]
+}
+
+// IsSupported reports whether numbers and abbreviations are expanded for the language
+func IsSupported(language string) bool {
+	return getLanguage(language) != nil
+}
+
+// IsAbbreviation reports whether a word, written without its final dot, is an abbreviation
+// of the language, e.g. "Mr" or "e.g" in English
+func IsAbbreviation(word, language string) bool {
+	lang := getLanguage(language)
+	if lang == nil {
+		return false
+	}
+
+	key := strings.ToLower(word)
+	_, ok := lang.abbreviations[key]
+	_, dotted := lang.abbreviations[key+"."]
+
+	return ok || dotted
+}
+
+// number spells a run of digits. Without a language, the digits are kept.
+func (lang *language) number(digits string) []string {
+	if lang == nil {
+		return []string{digits}
+	}
+
+	value, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || value > MAX_SPELLED_NUMBER || (len(digits) > 1 && digits[0] == '0') {
+		// Codes and numbers with leading zeros are read digit by digit
+		return lang.digits(digits)
+	}
+
+	if len(digits) == 4 && lang.year != nil {
+		return strings.Fields(lang.year(value))
+	}
+
+	return strings.Fields(lang.cardinal(value))
+}
+
+// digits spells each digit
+func (lang *language) digits(digits string) []string {
+	words := make([]string, 0, len(digits))
+	for _, digit := range digits {
+		words = append(words, lang.cardinal(int64(digit-'0')))
+	}
+	return words
+}
+
+// spellNumber spells a number with thousands or decimal separators,
+// it returns nil if it is not a valid number
+func (lang *language) spellNumber(numeric string) []string {
+	integer, fraction, ok := lang.parseNumber(numeric)
+	if !ok {
+		return nil
+	}
+
+	words := strings.Fields(lang.cardinal(integer))
+	if fraction == "" {
+		return words
+	}
+
+	words = append(words, strings.Fields(lang.decimalWord)...)
+	if lang.digitByDigit {
+		return append(words, lang.digits(fraction)...)
+	}
+
+	// Leading zeros of the decimals are read before the number, e.g. "zéro cinq"
+	trimmed := strings.TrimLeft(fraction, "0")
+	words = append(words, lang.digits(fraction[:len(fraction)-len(trimmed)])...)
+	if trimmed != "" {
+		words = append(words, lang.number(trimmed)...)
+	}
+	return words
+}
+
+// spellAmount spells a number followed by the currency name, with its minor unit
+func (lang *language) spellAmount(numeric, symbol string) []string {
+	currency, ok := lang.currencies[symbol]
+	if !ok {
+		return nil
+	}
+
+	integer, fraction, ok := lang.parseNumber(numeric)
+	if !ok {
+		return nil
+	}
+
+	name := currency.plural
+	if integer == 1 {
+		name = currency.singular
+	}
+	words := append(strings.Fields(lang.countCardinal(integer)), strings.Fields(name)...)
+
+	if fraction == "" {
+		return words
+	}
+	if currency.minorSingular == "" || len(fraction) > 2 {
+		return lang.spellNumber(numeric)
+	}
+
+	minor := atoi((fraction + "0")[:2])
+	if minor == 0 {
+		return words
+	}
+
+	minorName := currency.minorPlural
+	if minor == 1 {
+		minorName = currency.minorSingular
+	}
+	return append(append(words, strings.Fields(lang.countCardinal(minor))...), strings.Fields(minorName)...)
+}
+
+// countCardinal spells a number counting a noun, e.g. "un dólar" rather than "uno dólar"
+func (lang *language) countCardinal(n int64) string {
+	if lang.nounCardinal != nil {
+		return lang.nounCardinal(n)
+	}
+	return lang.cardinal(n)
+}
+
+// spellDate returns nil if the date is not valid
+func (lang *language) spellDate(day, month, year int64) []string {
+	if day < 1 || day > 31 || month < 1 || month > 12 || year > MAX_SPELLED_NUMBER {
+		return nil
+	}
+
+	return strings.Fields(lang.date(lang, day, month, year))
+}
+
+func (lang *language) spellTime(hours, minutes int64) []string {
+	return strings.Fields(lang.time(lang, hours, minutes))
+}
+
+// ordinalWords spells numbers with an ordinal suffix, it returns nil for other chunks
+func (lang *language) ordinalWords(chunk string) []string {
+	if lang.ordinalRegex == nil {
+		return nil
+	}
+
+	match := lang.ordinalRegex.FindStringSubmatch(chunk)
+	if match == nil {
+		return nil
+	}
+
+	value, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || value == 0 || value > MAX_SPELLED_NUMBER {
+		return nil
+	}
+
+	return strings.Fields(lang.ordinal(value))
+}
+
+// parseNumber splits a number into its integer value and decimal digits. Numbers written
+// with the separators of another language, e.g. 1,234.5 in French, are accepted.
+func (lang *language) parseNumber(numeric string) (int64, string, bool) {
+	thousandsSeparator := byte(',')
+	if lang.decimalSeparator == ',' {
+		thousandsSeparator = '.'
+	}
+
+	if integer, fraction, ok := parseSeparatedNumber(numeric, lang.decimalSeparator, thousandsSeparator); ok {
+		return integer, fraction, true
+	}
+	return parseSeparatedNumber(numeric, thousandsSeparator, lang.decimalSeparator)
+}
+
+func parseSeparatedNumber(numeric string, decimalSeparator, thousandsSeparator byte) (int64, string, bool) {
+	integerPart, fraction := numeric, ""
+	if i := strings.IndexByte(numeric, decimalSeparator); i >= 0 {
+		integerPart, fraction = numeric[:i], numeric[i+1:]
+		if !isDigits(fraction) {
+			return 0, "", false
+		}
+	}
+
+	groups := strings.Split(integerPart, string(thousandsSeparator))
+	for i, group := range groups {
+		if !isDigits(group) || (i > 0 && len(group) != 3) || (len(groups) > 1 && len(groups[0]) > 3) {
+			return 0, "", false
+		}
+	}
+
+	integer, err := strconv.ParseInt(strings.Join(groups, ""), 10, 64)
+	if err != nil || integer > MAX_SPELLED_NUMBER {
+		return 0, "", false
+	}
+
+	return integer, fraction, true
+}
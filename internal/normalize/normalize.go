@@ -0,0 +1,300 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+// Package normalize turns script and transcript texts into comparable words:
+// numbers, currencies, dates and abbreviations are spelled out for the supported
+// languages, diacritics are folded and punctuation is unified.
+package normalize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type Token struct {
+	Text   string // Normalized word
+	Source string // Original text the word comes from, shared by the words of an expansion
+	Start  int    // Byte offsets of the source in the text
+	End    int
+}
+
+var (
+	isoDateRegex     = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	numericDateRegex = regexp.MustCompile(`^(\d{1,2})[/.](\d{1,2})[/.](\d{2}|\d{4})$`)
+	timeRegex        = regexp.MustCompile(`^(\d{1,2})[:hH](\d{2})$`)
+	percentRegex     = regexp.MustCompile(`^([\d.,]*\d)%$`)
+	currencyRegex    = regexp.MustCompile(`^([$€£¥]?)([\d.,]*\d)([$€£¥]?)$`)
+	numberRegex      = regexp.MustCompile(`^[\d.,]*\d$`)
+	wordPartRegex    = regexp.MustCompile(`[\p{L}\p{M}]+(?:['’][\p{L}\p{M}]+)*|\d+`)
+	currencySymbols  = "$€£¥"
+)
+
+// Numbers above are read digit by digit
+const MAX_SPELLED_NUMBER = 999_999_999_999
+
+// Normalize returns the normalized words of the text, separated by spaces
+func Normalize(text, language string) string {
+	tokens := Tokenize(text, language)
+
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		words[i] = token.Text
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Tokenize splits the text into normalized words, keeping the position of their source
+func Tokenize(text, language string) []Token {
+	lang := getLanguage(language)
+
+	var chunks []chunk
+
+	start := -1
+	for i, r := range text + " " {
+		if !unicode.IsSpace(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+
+		if start >= 0 {
+			if trimmed := trimChunk(text, start, i); trimmed.start < trimmed.end {
+				chunks = append(chunks, trimmed)
+			}
+			start = -1
+		}
+	}
+
+	var tokens []Token
+
+	for i := 0; i < len(chunks); i++ {
+		current := chunks[i]
+
+		// Amounts and percentages are often written with a space before the symbol, e.g. 12,50 €
+		if i+1 < len(chunks) && isUnitSymbol(text[chunks[i+1].start:chunks[i+1].end]) {
+			next := chunks[i+1]
+			joined := UnifyPunctuation(text[current.start:current.end] + text[next.start:next.end])
+
+			if words := expandChunk(joined, next.hadDot, lang); words != nil {
+				tokens = append(tokens, expandedTokens(words, text[current.start:next.end], current.start, next.end)...)
+				i++
+				continue
+			}
+		}
+
+		tokens = append(tokens, tokenizeChunk(text, current, lang)...)
+	}
+
+	return tokens
+}
+
+// chunk is a part of the text between spaces, without its surrounding punctuation
+type chunk struct {
+	start  int
+	end    int
+	hadDot bool // The chunk was followed by a dot, e.g. an abbreviation
+}
+
+// trimChunk removes the punctuation around a text without spaces.
+// Currency symbols and % are part of the chunk.
+func trimChunk(text string, start, end int) chunk {
+	trimmed := strings.TrimLeftFunc(text[start:end], func(r rune) bool {
+		return !isWordRune(r) && !strings.ContainsRune(currencySymbols+"%", r)
+	})
+	start = end - len(trimmed)
+
+	hadDot := false
+	trimmed = strings.TrimRightFunc(trimmed, func(r rune) bool {
+		if r == '.' {
+			hadDot = true
+		}
+		return !isWordRune(r) && !strings.ContainsRune(currencySymbols+"%", r)
+	})
+
+	return chunk{start: start, end: start + len(trimmed), hadDot: hadDot}
+}
+
+// tokenizeChunk normalizes a chunk of the text
+func tokenizeChunk(text string, current chunk, lang *language) []Token {
+	source := text[current.start:current.end]
+
+	if words := expandChunk(UnifyPunctuation(source), current.hadDot, lang); words != nil {
+		return expandedTokens(words, source, current.start, current.end)
+	}
+
+	// Words and numbers inside the chunk, e.g. "COVID-19" or "l'été"
+	var tokens []Token
+	for _, index := range wordPartRegex.FindAllStringIndex(source, -1) {
+		part := source[index[0]:index[1]]
+
+		words := []string{part}
+		if isDigits(part) {
+			words = lang.number(part)
+		}
+
+		tokens = append(tokens, expandedTokens(words, part, current.start+index[0], current.start+index[1])...)
+	}
+
+	return tokens
+}
+
+// expandedTokens returns the tokens of words spelled out from the same source
+func expandedTokens(words []string, source string, start, end int) []Token {
+	var tokens []Token
+	for _, word := range words {
+		for _, field := range strings.Fields(word) {
+			tokens = append(tokens, Token{Text: foldWord(field), Source: source, Start: start, End: end})
+		}
+	}
+	return tokens
+}
+
+// expandChunk spells out the chunk when it is a date, time, amount, number or abbreviation.
+// It returns nil for other chunks.
+func expandChunk(chunk string, hadDot bool, lang *language) []string {
+	if lang == nil {
+		return nil
+	}
+
+	if match := isoDateRegex.FindStringSubmatch(chunk); match != nil {
+		if words := lang.spellDate(atoi(match[3]), atoi(match[2]), atoi(match[1])); words != nil {
+			return words
+		}
+	}
+
+	if match := numericDateRegex.FindStringSubmatch(chunk); match != nil {
+		first, second, year := atoi(match[1]), atoi(match[2]), atoi(match[3])
+		if len(match[3]) == 2 {
+			year += 2000
+		}
+
+		day, month := first, second
+		if lang.monthFirst {
+			day, month = second, first
+		}
+
+		if words := lang.spellDate(day, month, year); words != nil {
+			return words
+		}
+	}
+
+	if match := timeRegex.FindStringSubmatch(chunk); match != nil {
+		if hours, minutes := atoi(match[1]), atoi(match[2]); hours < 24 && minutes < 60 {
+			return lang.spellTime(hours, minutes)
+		}
+	}
+
+	if match := percentRegex.FindStringSubmatch(chunk); match != nil {
+		if words := lang.spellNumber(match[1]); words != nil {
+			return append(words, lang.percent)
+		}
+	}
+
+	if match := currencyRegex.FindStringSubmatch(chunk); match != nil && (match[1] != "") != (match[3] != "") {
+		if words := lang.spellAmount(match[2], match[1]+match[3]); words != nil {
+			return words
+		}
+	}
+
+	if isUnitSymbol(chunk) {
+		if chunk == "%" {
+			return []string{lang.percent}
+		}
+		if currency, ok := lang.currencies[chunk]; ok {
+			return []string{currency.plural}
+		}
+	}
+
+	if numberRegex.MatchString(chunk) && !isDigits(chunk) {
+		return lang.spellNumber(chunk)
+	}
+
+	if words := lang.ordinalWords(chunk); words != nil {
+		return words
+	}
+
+	key := strings.ToLower(chunk)
+	if words, ok := lang.abbreviations[key]; ok {
+		return words
+	}
+	if hadDot {
+		if words, ok := lang.abbreviations[key+"."]; ok {
+			return words
+		}
+	}
+
+	return nil
+}
+
+// UnifyPunctuation replaces typographic quotes, dashes, ellipses and spaces by their ASCII form
+func UnifyPunctuation(text string) string {
+	return punctuationReplacer.Replace(text)
+}
+
+var punctuationReplacer = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "`", "'", "´", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "«", `"`, "»", `"`,
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-", "―", "-", "−", "-",
+	"…", "...",
+	"\u00a0", " ", "\u202f", " ", "\u2009", " ", // Non-breaking and thin spaces
+)
+
+// Fold lowercases the word and removes its diacritics
+func Fold(word string) string {
+	var builder strings.Builder
+
+	for _, r := range norm.NFD.String(strings.ToLower(word)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		if replacement, ok := foldedLetters[r]; ok {
+			builder.WriteString(replacement)
+			continue
+		}
+
+		builder.WriteRune(r)
+	}
+
+	return norm.NFC.String(builder.String())
+}
+
+// foldWord folds a word and removes its apostrophes, e.g. l'été is lete
+func foldWord(word string) string {
+	return Fold(strings.ReplaceAll(UnifyPunctuation(word), "'", ""))
+}
+
+// Letters without a canonical decomposition
+var foldedLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ł': "l", 'ı': "i", 'þ': "th", 'ð': "d",
+}
+
+// isUnitSymbol reports whether the text is a single currency symbol or %
+func isUnitSymbol(text string) bool {
+	return text == "%" || (utf8.RuneCountInString(text) == 1 && strings.Contains(currencySymbols, text))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+func isDigits(text string) bool {
+	for _, r := range text {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return text != ""
+}
+
+func atoi(text string) int64 {
+	value, _ := strconv.ParseInt(text, 10, 64)
+	return value
+}
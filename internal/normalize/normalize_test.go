@@ -0,0 +1,193 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package normalize
+
+import "testing"
+
+type normalizeCase struct {
+	name string
+	text string
+	want string
+}
+
+func runNormalizeCases(t *testing.T, language string, cases []normalizeCase) {
+	t.Helper()
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Normalize(c.text, language); got != c.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", c.text, language, got, c.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEnglish(t *testing.T) {
+	runNormalizeCases(t, "en", []normalizeCase{
+		// Numbers
+		{"cardinal", "I have 42 apples", "i have forty two apples"},
+		{"thousands separator", "1,234 people", "one thousand two hundred thirty four people"},
+		{"decimal", "3.14", "three point one four"},
+		{"year", "in 1995", "in nineteen ninety five"},
+		{"leading zero", "agent 007", "agent zero zero seven"},
+		{"ordinal", "the 21st", "the twenty first"},
+		{"inside a word", "COVID-19", "covid nineteen"},
+
+		// Currencies
+		{"dollars", "$5", "five dollars"},
+		{"singular with cents", "$1.50", "one dollar fifty cents"},
+		{"pounds and penny", "£2.01", "two pounds one penny"},
+		{"symbol after a space", "12,50 €", "twelve euros fifty cents"},
+		{"percent", "25%", "twenty five percent"},
+
+		// Dates and times
+		{"numeric date", "1/15/2024", "january fifteenth twenty twenty four"},
+		{"iso date", "2024-03-01", "march first twenty twenty four"},
+		{"time", "at 9:05", "at nine oh five"},
+		{"full hour", "at 10:00", "at ten oclock"},
+
+		// Abbreviations
+		{"titles", "Mr. Brown and Dr. Smith", "mister brown and doctor smith"},
+		{"dotted only", "St. Louis", "saint louis"},
+		{"without its dot", "no st here", "no st here"},
+		{"inner dots", "e.g. this", "for example this"},
+
+		// Diacritics
+		{"accents", "Café déjà vu", "cafe deja vu"},
+		{"ligature", "Œuvre naïve", "oeuvre naive"},
+
+		// Punctuation
+		{"typographic", "“Quoted” — dash… done", "quoted dash done"},
+		{"apostrophes", "It’s here, it's", "its here its"},
+	})
+}
+
+func TestNormalizeFrench(t *testing.T) {
+	runNormalizeCases(t, "fr", []normalizeCase{
+		// Numbers
+		{"cardinal", "J'ai 42 pommes", "jai quarante deux pommes"},
+		{"et un", "21", "vingt et un"},
+		{"soixante et onze", "71", "soixante et onze"},
+		{"quatre vingts", "80", "quatre vingts"},
+		{"quatre vingt dix", "99", "quatre vingt dix neuf"},
+		{"decimal", "3,14", "trois virgule quatorze"},
+		{"year", "en 2024", "en deux mille vingt quatre"},
+		{"premier", "le 1er", "le premier"},
+		{"ordinal", "la 2e", "la deuxieme"},
+
+		// Currencies
+		{"euros and centimes", "12,50 €", "douze euros cinquante centimes"},
+		{"one euro", "1 €", "un euro"},
+		{"percent after a space", "1,5 %", "un virgule cinq pour cent"},
+		{"percent", "25%", "vingt cinq pour cent"},
+
+		// Dates and times
+		{"numeric date", "15/01/2024", "quinze janvier deux mille vingt quatre"},
+		{"first of the month", "1/5/2024", "premier mai deux mille vingt quatre"},
+		{"time with h", "à 9h05", "a neuf heures cinq"},
+		{"time with colon", "14:30", "quatorze heures trente"},
+
+		// Abbreviations
+		{"titles", "M. Dupont et Mme Durand", "monsieur dupont et madame durand"},
+		{"without dot", "Dr Martin", "docteur martin"},
+		{"m without its dot", "m et n", "m et n"},
+		{"c'est-à-dire", "c.-à-d. rien", "cest a dire rien"},
+
+		// Diacritics
+		{"accents", "Éléphant à l’école", "elephant a lecole"},
+		{"cedilla", "Ça reçu", "ca recu"},
+
+		// Punctuation
+		{"guillemets", "« guillemets »", "guillemets"},
+		{"spaced punctuation", "Quoi ? Non !", "quoi non"},
+	})
+}
+
+func TestNormalizeSpanish(t *testing.T) {
+	runNormalizeCases(t, "es", []normalizeCase{
+		// Numbers
+		{"cardinal", "Tengo 42 manzanas", "tengo cuarenta y dos manzanas"},
+		{"cien", "100", "cien"},
+		{"ciento", "101", "ciento uno"},
+		{"quinientos", "500", "quinientos"},
+		{"thousands separator", "1.234", "mil doscientos treinta y cuatro"},
+		{"millon", "1.000.000", "un millon"},
+		{"decimal", "3,14", "tres coma catorce"},
+		{"ordinal", "el 1º", "el primero"},
+
+		// Currencies
+		{"euros and centimos", "12,50 €", "doce euros cincuenta centimos"},
+		{"one dollar", "$1", "un dolar"},
+		{"twenty one euros", "21 €", "veintiun euros"},
+		{"percent", "25%", "veinticinco por ciento"},
+
+		// Dates and times
+		{"numeric date", "15/01/2024", "quince de enero de dos mil veinticuatro"},
+		{"time", "a las 9:05", "a las nueve y cinco"},
+		{"full hour", "a las 10:00", "a las diez"},
+
+		// Abbreviations
+		{"titles", "Sr. García y la Sra. López", "senor garcia y la senora lopez"},
+		{"usted", "Ud.", "usted"},
+		{"inner dots", "p.ej. esto", "por ejemplo esto"},
+
+		// Diacritics
+		{"enye", "niño año", "nino ano"},
+		{"accents", "canción rápida", "cancion rapida"},
+
+		// Punctuation
+		{"inverted question", "¿Qué pasó?", "que paso"},
+		{"inverted exclamation", "¡Olé!", "ole"},
+	})
+}
+
+func TestNormalizeUnsupportedLanguage(t *testing.T) {
+	// Numbers are kept, diacritics and punctuation are still handled
+	if got, want := Normalize("Ça fait 42 €, d’accord ?", "ja"), "ca fait 42 daccord"; got != want {
+		t.Errorf("Normalize = %q, want %q", got, want)
+	}
+}
+
+func TestTokenizeKeepsSources(t *testing.T) {
+	tokens := Tokenize("Dr. Who paid $5.", "en")
+
+	want := []Token{
+		{Text: "doctor", Source: "Dr", Start: 0, End: 2},
+		{Text: "who", Source: "Who", Start: 4, End: 7},
+		{Text: "paid", Source: "paid", Start: 8, End: 12},
+		{Text: "five", Source: "$5", Start: 13, End: 15},
+		{Text: "dollars", Source: "$5", Start: 13, End: 15},
+	}
+
+	if len(tokens) != len(want) {
+		t.Fatalf("Tokenize returned %d tokens, want %d: %+v", len(tokens), len(want), tokens)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("token %d = %+v, want %+v", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestIsAbbreviation(t *testing.T) {
+	cases := []struct {
+		word     string
+		language string
+		want     bool
+	}{
+		{"Mr", "en", true},
+		{"e.g", "en", true},
+		{"St", "en", true},
+		{"home", "en", false},
+		{"M", "fr", true},
+		{"Sra", "es", true},
+		{"Mr", "ja", false},
+	}
+
+	for _, c := range cases {
+		if got := IsAbbreviation(c.word, c.language); got != c.want {
+			t.Errorf("IsAbbreviation(%q, %q) = %v, want %v", c.word, c.language, got, c.want)
+		}
+	}
+}
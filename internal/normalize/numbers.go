@@ -0,0 +1,374 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package normalize
+
+import (
+	"strings"
+)
+
+// Spelled numbers are returned as words separated by spaces
+
+type numberScale struct {
+	value int64
+	name  string
+}
+
+// --- English ---
+
+var englishOnes = []string{
+	"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine",
+	"ten", "eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen",
+}
+
+var englishTens = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+
+var englishScales = []numberScale{{1_000_000_000, "billion"}, {1_000_000, "million"}, {1_000, "thousand"}}
+
+func englishCardinal(n int64) string {
+	switch {
+	case n < 20:
+		return englishOnes[n]
+	case n < 100:
+		return joinWords(englishTens[n/10], nonZero(n%10, englishCardinal))
+	case n < 1000:
+		return joinWords(englishOnes[n/100], "hundred", nonZero(n%100, englishCardinal))
+	}
+
+	for _, scale := range englishScales {
+		if n >= scale.value {
+			return joinWords(englishCardinal(n/scale.value), scale.name, nonZero(n%scale.value, englishCardinal))
+		}
+	}
+
+	return ""
+}
+
+var englishOrdinalWords = map[string]string{
+	"one": "first", "two": "second", "three": "third", "five": "fifth",
+	"eight": "eighth", "nine": "ninth", "twelve": "twelfth",
+}
+
+func englishOrdinal(n int64) string {
+	return replaceLastWord(englishCardinal(n), func(word string) string {
+		if ordinal, ok := englishOrdinalWords[word]; ok {
+			return ordinal
+		}
+		if strings.HasSuffix(word, "y") {
+			return strings.TrimSuffix(word, "y") + "ieth"
+		}
+		return word + "th"
+	})
+}
+
+// englishYear reads years by pairs of digits, e.g. 1999 is nineteen ninety-nine
+func englishYear(n int64) string {
+	if n < 1100 || n > 2099 || (n >= 2000 && n < 2010) {
+		return englishCardinal(n)
+	}
+
+	century, rest := n/100, n%100
+
+	switch {
+	case rest == 0:
+		return joinWords(englishCardinal(century), "hundred")
+	case rest < 10:
+		return joinWords(englishCardinal(century), "oh", englishOnes[rest])
+	default:
+		return joinWords(englishCardinal(century), englishCardinal(rest))
+	}
+}
+
+// --- French ---
+
+var frenchUnits = []string{
+	"zéro", "un", "deux", "trois", "quatre", "cinq", "six", "sept", "huit", "neuf",
+	"dix", "onze", "douze", "treize", "quatorze", "quinze", "seize",
+}
+
+var frenchTens = []string{"", "dix", "vingt", "trente", "quarante", "cinquante", "soixante"}
+
+func frenchBelow100(n int64) string {
+	switch {
+	case n <= 16:
+		return frenchUnits[n]
+	case n < 20:
+		return joinWords("dix", frenchUnits[n-10])
+	case n < 70:
+		switch unit := n % 10; unit {
+		case 0:
+			return frenchTens[n/10]
+		case 1:
+			return joinWords(frenchTens[n/10], "et un")
+		default:
+			return joinWords(frenchTens[n/10], frenchUnits[unit])
+		}
+	case n < 80:
+		if n == 71 {
+			return "soixante et onze"
+		}
+		return joinWords("soixante", frenchBelow100(n-60))
+	case n == 80:
+		return "quatre vingts"
+	default:
+		return joinWords("quatre vingt", frenchBelow100(n-80))
+	}
+}
+
+func frenchCardinal(n int64) string {
+	switch {
+	case n < 100:
+		return frenchBelow100(n)
+	case n < 1000:
+		hundreds, rest := n/100, n%100
+		word := "cent"
+		if hundreds > 1 {
+			word = joinWords(frenchUnits[hundreds], "cent")
+			if rest == 0 {
+				word += "s"
+			}
+		}
+		return joinWords(word, nonZero(rest, frenchBelow100))
+	case n < 1_000_000:
+		thousands, rest := n/1000, n%1000
+		word := "mille"
+		if thousands > 1 {
+			word = joinWords(frenchCardinal(thousands), "mille")
+		}
+		return joinWords(word, nonZero(rest, frenchCardinal))
+	case n < 1_000_000_000:
+		return joinWords(frenchCardinal(n/1_000_000), plural("million", n/1_000_000), nonZero(n%1_000_000, frenchCardinal))
+	default:
+		return joinWords(frenchCardinal(n/1_000_000_000), plural("milliard", n/1_000_000_000), nonZero(n%1_000_000_000, frenchCardinal))
+	}
+}
+
+func frenchOrdinal(n int64) string {
+	if n == 1 {
+		return "premier"
+	}
+
+	return replaceLastWord(frenchCardinal(n), func(word string) string {
+		switch {
+		case word == "un":
+			return "unième"
+		case word == "cinq":
+			return "cinquième"
+		case word == "neuf":
+			return "neuvième"
+		case word == "cents" || word == "vingts":
+			return strings.TrimSuffix(word, "s") + "ième"
+		case strings.HasSuffix(word, "e"):
+			return strings.TrimSuffix(word, "e") + "ième"
+		default:
+			return word + "ième"
+		}
+	})
+}
+
+// --- Spanish ---
+
+var spanishUnits = []string{
+	"cero", "uno", "dos", "tres", "cuatro", "cinco", "seis", "siete", "ocho", "nueve",
+	"diez", "once", "doce", "trece", "catorce", "quince", "dieciséis", "diecisiete", "dieciocho", "diecinueve",
+	"veinte", "veintiuno", "veintidós", "veintitrés", "veinticuatro", "veinticinco", "veintiséis", "veintisiete", "veintiocho", "veintinueve",
+}
+
+var spanishTens = []string{"", "", "", "treinta", "cuarenta", "cincuenta", "sesenta", "setenta", "ochenta", "noventa"}
+
+var spanishHundreds = []string{
+	"", "ciento", "doscientos", "trescientos", "cuatrocientos", "quinientos", "seiscientos", "setecientos", "ochocientos", "novecientos",
+}
+
+var spanishOrdinals = []string{
+	"", "primero", "segundo", "tercero", "cuarto", "quinto", "sexto", "séptimo", "octavo", "noveno", "décimo",
+}
+
+func spanishCardinal(n int64) string {
+	switch {
+	case n < 30:
+		return spanishUnits[n]
+	case n < 100:
+		if n%10 == 0 {
+			return spanishTens[n/10]
+		}
+		return joinWords(spanishTens[n/10], "y", spanishUnits[n%10])
+	case n == 100:
+		return "cien"
+	case n < 1000:
+		return joinWords(spanishHundreds[n/100], nonZero(n%100, spanishCardinal))
+	case n < 1_000_000:
+		thousands, rest := n/1000, n%1000
+		word := "mil"
+		if thousands > 1 {
+			word = joinWords(spanishApocope(spanishCardinal(thousands)), "mil")
+		}
+		return joinWords(word, nonZero(rest, spanishCardinal))
+	default:
+		// Up to "mil millones" and above
+		millions, rest := n/1_000_000, n%1_000_000
+		word := "un millón"
+		if millions > 1 {
+			word = joinWords(spanishApocope(spanishCardinal(millions)), "millones")
+		}
+		return joinWords(word, nonZero(rest, spanishCardinal))
+	}
+}
+
+func spanishNounCardinal(n int64) string {
+	return spanishApocope(spanishCardinal(n))
+}
+
+func spanishOrdinal(n int64) string {
+	if n < int64(len(spanishOrdinals)) {
+		return spanishOrdinals[n]
+	}
+	return spanishCardinal(n)
+}
+
+// spanishApocope shortens "uno" before a noun, e.g. veintiún mil
+func spanishApocope(words string) string {
+	return replaceLastWord(words, func(word string) string {
+		if word == "veintiuno" {
+			return "veintiún"
+		}
+		if strings.HasSuffix(word, "uno") {
+			return strings.TrimSuffix(word, "o")
+		}
+		return word
+	})
+}
+
+// --- German ---
+
+var germanOnes = []string{
+	"null", "eins", "zwei", "drei", "vier", "fünf", "sechs", "sieben", "acht", "neun",
+	"zehn", "elf", "zwölf", "dreizehn", "vierzehn", "fünfzehn", "sechzehn", "siebzehn", "achtzehn", "neunzehn",
+}
+
+var germanTens = []string{"", "", "zwanzig", "dreißig", "vierzig", "fünfzig", "sechzig", "siebzig", "achtzig", "neunzig"}
+
+// Numbers below a million are written as a single word
+func germanBelowMillion(n int64) string {
+	switch {
+	case n < 20:
+		return germanOnes[n]
+	case n < 100:
+		if n%10 == 0 {
+			return germanTens[n/10]
+		}
+		return germanPrefix(n%10) + "und" + germanTens[n/10]
+	case n < 1000:
+		word := germanPrefix(n/100) + "hundert"
+		if n/100 == 1 {
+			word = "hundert"
+		}
+		if n%100 != 0 {
+			word += germanBelowMillion(n % 100)
+		}
+		return word
+	default:
+		word := germanPrefix(n/1000) + "tausend"
+		if n/1000 == 1 {
+			word = "tausend"
+		}
+		if n%1000 != 0 {
+			word += germanBelowMillion(n % 1000)
+		}
+		return word
+	}
+}
+
+func germanCardinal(n int64) string {
+	switch {
+	case n < 1_000_000:
+		return germanBelowMillion(n)
+	case n < 1_000_000_000:
+		millions := n / 1_000_000
+		word := "eine million"
+		if millions > 1 {
+			word = joinWords(germanCardinal(millions), "millionen")
+		}
+		return joinWords(word, nonZero(n%1_000_000, germanCardinal))
+	default:
+		billions := n / 1_000_000_000
+		word := "eine milliarde"
+		if billions > 1 {
+			word = joinWords(germanCardinal(billions), "milliarden")
+		}
+		return joinWords(word, nonZero(n%1_000_000_000, germanCardinal))
+	}
+}
+
+// germanPrefix is the form of a number before another one, e.g. "ein" in einundzwanzig
+func germanPrefix(n int64) string {
+	word := germanBelowMillion(n)
+	if strings.HasSuffix(word, "eins") {
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
+
+func germanOrdinal(n int64) string {
+	switch n {
+	case 1:
+		return "erster"
+	case 3:
+		return "dritter"
+	case 7:
+		return "siebter"
+	case 8:
+		return "achter"
+	}
+
+	if n < 20 {
+		return germanCardinal(n) + "ter"
+	}
+	return germanCardinal(n) + "ster"
+}
+
+// germanYear reads the years before 2000 by hundreds, e.g. neunzehnhundertneunundneunzig
+func germanYear(n int64) string {
+	if n < 1100 || n > 1999 {
+		return germanCardinal(n)
+	}
+
+	word := germanBelowMillion(n/100) + "hundert"
+	if n%100 != 0 {
+		word += germanBelowMillion(n % 100)
+	}
+	return word
+}
+
+// --- Helpers ---
+
+// joinWords joins the non empty words with spaces
+func joinWords(words ...string) string {
+	var parts []string
+	for _, word := range words {
+		if word != "" {
+			parts = append(parts, word)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// nonZero spells n, or returns an empty string for 0
+func nonZero(n int64, spell func(int64) string) string {
+	if n == 0 {
+		return ""
+	}
+	return spell(n)
+}
+
+func plural(word string, n int64) string {
+	if n > 1 {
+		return word + "s"
+	}
+	return word
+}
+
+func replaceLastWord(words string, replace func(string) string) string {
+	index := strings.LastIndex(words, " ")
+	return words[:index+1] + replace(words[index+1:])
+}
@@ -15,7 +15,8 @@ import (
 
 const (
 	OPERATION_SAVE   = "SAVE"
-	OPERATION_DELETE = "DELETE"
+	OPERATION_DELETE = "DELETE" // Soft delete
+	OPERATION_PURGE  = "PURGE"  // Permanent delete
 )
 
 type BaseRepository struct {
@@ -39,6 +40,14 @@ func (base *BaseUUIDModel) BeforeCreate(tx *gorm.DB) error {
 
 type MapUpdate = map[string]interface{}
 
+// deleteOperation tells a soft delete from a permanent one, made with Unscoped
+func deleteOperation(tx *gorm.DB) string {
+	if tx.Statement.Unscoped {
+		return OPERATION_PURGE
+	}
+	return OPERATION_DELETE
+}
+
 func GetModelID(model interface{}) string {
 	val := reflect.ValueOf(model)
 
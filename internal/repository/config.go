@@ -16,6 +16,9 @@ type Config struct {
 	TranscriberSource string  `gorm:"column:transcriber_source;default:local"` // local, openai, witai, groq
 	LocalWhisperModel *string `gorm:"column:local_whisper_model"`
 	LocalWhisperGPU   *bool   `gorm:"column:local_whisper_gpu"`
+
+	RecordSessionAudio *bool `gorm:"column:record_session_audio"` // Save the audio of reading sessions
+	TrashRetentionDays *int  `gorm:"column:trash_retention_days"` // Days before deleted pages are purged, 0 to keep them
 }
 
 // Hooks
@@ -0,0 +1,77 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UNSYNCED MODEL
+
+// Microphone devices are specific to each machine, so their settings are not synced
+type MicrophoneSetting struct {
+	gorm.Model
+	DeviceName string `gorm:"uniqueIndex"`
+
+	// Calibration
+	NoiseFloor      *float64
+	NoiseVariance   *float64
+	SpeechLevel     *float64
+	CalibratedAt    *time.Time
+	NoiseThreshold  *float64 // nil uses the sequencer default
+	TriggerDecibels *float64 // nil uses the sequencer default
+
+	// Voice activity detection
+	VoiceDetector *string // decibel, spectral - nil uses the sequencer default
+
+	// Multi-channel interfaces
+	CaptureChannels *uint32 // Channels captured from the device - nil lets the system downmix
+	InputChannels   *string // 1-based channels mixed for transcription, e.g. "2" or "1,2" - nil mixes all
+
+	// Pre-processing - nil is disabled
+	HighPassFilter *bool
+	NoiseReduction *bool
+	AutoGain       *bool
+	AutoGainTarget *float64 // dBFS - nil uses the sequencer default
+
+	// Device lost during a recording
+	AutoFallback       *bool   // Switch to the fallback device - nil is enabled
+	FallbackDeviceName *string // nil uses the system default device
+}
+
+type MicrophoneSettingRepository struct {
+	BaseRepository
+}
+
+func NewMicrophoneSettingRepository(unSyncedDB *gorm.DB) *MicrophoneSettingRepository {
+	return &MicrophoneSettingRepository{
+		BaseRepository: BaseRepository{db: unSyncedDB},
+	}
+}
+
+func (r *MicrophoneSettingRepository) GetMicrophoneSetting(deviceName string) *MicrophoneSetting {
+	var setting MicrophoneSetting
+
+	if err := r.db.Where("device_name = ?", deviceName).First(&setting).Error; err != nil {
+		return &MicrophoneSetting{DeviceName: deviceName}
+	}
+
+	return &setting
+}
+
+func (r *MicrophoneSettingRepository) SaveMicrophoneSetting(setting *MicrophoneSetting) *MicrophoneSetting {
+	existing := r.GetMicrophoneSetting(setting.DeviceName)
+	setting.ID = existing.ID
+	setting.CreatedAt = existing.CreatedAt
+
+	r.db.Save(setting)
+
+	return setting
+}
+
+func (r *MicrophoneSettingRepository) DeleteMicrophoneSetting(deviceName string) {
+	r.db.Unscoped().Where("device_name = ?", deviceName).Delete(&MicrophoneSetting{})
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"myscript/internal/utils"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// !SYNCED MODEL
+
+// PageRevision is a copy of the content of a page, captured when it is saved
+type PageRevision struct {
+	BaseUUIDModel
+
+	PageID      string         `json:"page_id" gorm:"index"`
+	Title       string         `json:"title"`
+	HtmlContent string         `json:"html_content"`
+	Blocks      datatypes.JSON `json:"blocks"`
+	Hash        string         `json:"hash"` // Of the title and the HTML content
+}
+
+// Hooks
+func (n *PageRevision) AfterCreate(tx *gorm.DB) error {
+	return logChange(tx, n, OPERATION_SAVE)
+}
+
+func (n *PageRevision) AfterUpdate(tx *gorm.DB) error {
+	return logChange(tx, n, OPERATION_SAVE)
+}
+
+func (n *PageRevision) AfterDelete(tx *gorm.DB) error {
+	return logChange(tx, n, deleteOperation(tx))
+}
+
+const (
+	// Saves within this interval form an editing burst, only its first snapshot and its latest save are kept
+	PAGE_REVISION_INTERVAL = 10 * time.Minute
+
+	// Retention policy, the latest revision of a page is always kept
+	PAGE_REVISION_MAX_COUNT = 50
+	PAGE_REVISION_MAX_AGE   = 90 * 24 * time.Hour
+)
+
+type PageRevisionDiff struct {
+	From    *PageRevision    `json:"from"`
+	To      *PageRevision    `json:"to"`
+	Title   []utils.DiffPart `json:"title"`
+	Content []utils.DiffPart `json:"content"` // Of the text of the HTML content
+}
+
+type PageRevisionRepository struct {
+	BaseRepository
+}
+
+func NewPageRevisionRepository(db *gorm.DB) *PageRevisionRepository {
+	return &PageRevisionRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetPageRevisions returns the revisions of a page without their content, latest first
+func (r *PageRevisionRepository) GetPageRevisions(pageID string) []PageRevision {
+	var revisions []PageRevision
+
+	r.db.Omit("html_content", "blocks").
+		Where("page_id = ?", pageID).
+		Order("created_at desc").
+		Find(&revisions)
+
+	return revisions
+}
+
+func (r *PageRevisionRepository) GetPageRevision(ID string) *PageRevision {
+	var revision PageRevision
+
+	if err := r.db.First(&revision, "id = ?", ID).Error; err != nil {
+		return nil
+	}
+
+	return &revision
+}
+
+func (r *PageRevisionRepository) getLatestRevision(pageID string) *PageRevision {
+	revisions := r.getLatestRevisions(pageID, 1)
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	return &revisions[0]
+}
+
+// getLatestRevisions returns the latest revisions of a page without their content, latest first
+func (r *PageRevisionRepository) getLatestRevisions(pageID string, count int) []PageRevision {
+	var revisions []PageRevision
+
+	r.db.Omit("html_content", "blocks").
+		Where("page_id = ?", pageID).
+		Order("created_at desc").
+		Limit(count).
+		Find(&revisions)
+
+	return revisions
+}
+
+// CaptureRevision records the content of a saved page.
+// Unchanged content is skipped. During an editing burst, each save updates the revision
+// of the burst in place, so the snapshot taken before the burst is never overwritten.
+func (r *PageRevisionRepository) CaptureRevision(page *Page) {
+	r.captureRevision(page, true)
+}
+
+func (r *PageRevisionRepository) captureRevision(page *Page, throttle bool) {
+	if page.IsFolder || page.ID == "" {
+		return
+	}
+
+	hash := pageContentHash(page)
+	revisions := r.getLatestRevisions(page.ID, 2)
+
+	if len(revisions) > 0 && revisions[0].Hash == hash {
+		return
+	}
+
+	// The latest revision is an intermediate save of the ongoing burst when it closely
+	// follows the previous one, the newer save supersedes its content
+	if throttle && len(revisions) == 2 &&
+		time.Since(revisions[0].CreatedAt) < PAGE_REVISION_INTERVAL &&
+		revisions[0].CreatedAt.Sub(revisions[1].CreatedAt) < PAGE_REVISION_INTERVAL {
+		burst := revisions[0]
+		burst.Title = page.Title
+		burst.HtmlContent = page.HtmlContent
+		burst.Blocks = page.Blocks
+		burst.Hash = hash
+
+		r.db.Save(&burst)
+		return
+	}
+
+	r.db.Create(&PageRevision{
+		PageID:      page.ID,
+		Title:       page.Title,
+		HtmlContent: page.HtmlContent,
+		Blocks:      page.Blocks,
+		Hash:        hash,
+	})
+
+	r.applyRetention(page.ID)
+}
+
+// applyRetention permanently deletes the revisions of a page beyond the maximum count or age
+func (r *PageRevisionRepository) applyRetention(pageID string) {
+	revisions := r.GetPageRevisions(pageID)
+	maxAge := time.Now().Add(-PAGE_REVISION_MAX_AGE)
+
+	for i := 1; i < len(revisions); i++ {
+		if i >= PAGE_REVISION_MAX_COUNT || revisions[i].CreatedAt.Before(maxAge) {
+			r.db.Unscoped().Delete(&revisions[i])
+		}
+	}
+}
+
+// DiffPageRevisions compares two revisions of a page, word by word.
+// Without toID, the revision is compared with the current content of the page.
+func (r *PageRevisionRepository) DiffPageRevisions(fromID, toID string) *PageRevisionDiff {
+	from := r.GetPageRevision(fromID)
+	if from == nil {
+		return nil
+	}
+
+	var to *PageRevision
+	if toID != "" {
+		to = r.GetPageRevision(toID)
+	} else if page := NewPageRepository(r.db).GetPage(from.PageID); page.ID != "" {
+		to = &PageRevision{
+			PageID:      page.ID,
+			Title:       page.Title,
+			HtmlContent: page.HtmlContent,
+			Blocks:      page.Blocks,
+			Hash:        pageContentHash(page),
+		}
+		to.CreatedAt = page.UpdatedAt
+	}
+
+	if to == nil {
+		return nil
+	}
+
+	return &PageRevisionDiff{
+		From:    from,
+		To:      to,
+		Title:   utils.DiffWords(from.Title, to.Title),
+		Content: utils.DiffWords(utils.HTMLToText(from.HtmlContent), utils.HTMLToText(to.HtmlContent)),
+	}
+}
+
+// RestorePageRevision saves the content of a revision as the content of its page.
+// The restore is a revision of its own, so the replaced content stays in the history.
+func (r *PageRevisionRepository) RestorePageRevision(ID string) *Page {
+	revision := r.GetPageRevision(ID)
+	if revision == nil {
+		return nil
+	}
+
+	page := NewPageRepository(r.db).GetPage(revision.PageID)
+	if page.ID == "" {
+		return nil
+	}
+
+	page.Title = revision.Title
+	page.HtmlContent = revision.HtmlContent
+	page.Blocks = revision.Blocks
+
+	r.db.Save(page)
+	r.captureRevision(page, false)
+
+	return page
+}
+
+// DeletePageRevision permanently deletes a revision, its content is not kept in the trash
+func (r *PageRevisionRepository) DeletePageRevision(ID string) {
+	var revision PageRevision
+
+	if err := r.db.First(&revision, "id = ?", ID).Error; err != nil {
+		return
+	}
+
+	r.db.Unscoped().Delete(&revision)
+}
+
+func pageContentHash(page *Page) string {
+	hash := sha256.Sum256([]byte(page.Title + "\x00" + page.HtmlContent))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newRevisionDatabases returns a main database with the pages and their revisions,
+// the changes are logged into a separate unsynced database
+func newRevisionDatabases(t *testing.T) (*gorm.DB, *gorm.DB) {
+	t.Helper()
+
+	open := func(name string, models ...interface{}) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name)), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(models...); err != nil {
+			t.Fatal(err)
+		}
+		return db
+	}
+
+	db := open("database.sqlite", &Page{}, &PageRevision{})
+	unSynced := open("unsynced-database.sqlite", &ChangeLog{})
+
+	SetUnSyncedDB(unSynced)
+	t.Cleanup(func() { SetUnSyncedDB(nil) })
+
+	return db, unSynced
+}
+
+func revisionChanges(t *testing.T, unSynced *gorm.DB) []ChangeLog {
+	t.Helper()
+
+	var changes []ChangeLog
+	if err := unSynced.Where("table_name = ?", "page_revisions").Order("id").Find(&changes).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return changes
+}
+
+func TestCaptureRevisionBurst(t *testing.T) {
+	db, unSynced := newRevisionDatabases(t)
+	pages := NewPageRepository(db)
+	revisions := NewPageRevisionRepository(db)
+
+	page := pages.SavePage(&Page{Title: "Opening", HtmlContent: "<p>Good evening.</p>"})
+
+	// Autosaves of an editing burst
+	for _, content := range []string{"<p>Good evening, everyone.</p>", "<p>Good evening, all.</p>", "<p>Hello, all.</p>"} {
+		page.HtmlContent = content
+		pages.SavePage(page)
+	}
+
+	history := revisions.GetPageRevisions(page.ID)
+	if len(history) != 2 {
+		t.Fatalf("got %d revisions, want the snapshot before the burst and the burst", len(history))
+	}
+	if first := revisions.GetPageRevision(history[1].ID); first.HtmlContent != "<p>Good evening.</p>" {
+		t.Errorf("snapshot before the burst = %q, want the first save", first.HtmlContent)
+	}
+	if burst := revisions.GetPageRevision(history[0].ID); burst.HtmlContent != "<p>Hello, all.</p>" {
+		t.Errorf("burst revision = %q, want the latest save", burst.HtmlContent)
+	}
+
+	// One change per revision, the burst revision is saved again instead of being replaced
+	changes := revisionChanges(t, unSynced)
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2: %+v", len(changes), changes)
+	}
+	for _, change := range changes {
+		if change.Operation != OPERATION_SAVE {
+			t.Errorf("change %s operation = %q, want %q", change.RowID, change.Operation, OPERATION_SAVE)
+		}
+	}
+
+	// Once the burst window has elapsed, the next save is a new revision
+	for _, revision := range history {
+		createdAt := revision.CreatedAt.Add(-PAGE_REVISION_INTERVAL)
+		if err := db.Model(&revision).UpdateColumn("created_at", createdAt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page.HtmlContent = "<p>Welcome.</p>"
+	pages.SavePage(page)
+
+	if history := revisions.GetPageRevisions(page.ID); len(history) != 3 {
+		t.Errorf("got %d revisions after the burst window, want 3", len(history))
+	}
+	if changes := revisionChanges(t, unSynced); len(changes) != 3 {
+		t.Errorf("got %d changes after the burst window, want 3", len(changes))
+	}
+}
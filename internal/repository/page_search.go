@@ -0,0 +1,268 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"html"
+	"log/slog"
+	"myscript/internal/utils"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// UNSYNCED INDEX
+
+// The page_search FTS5 table indexes the title and the text of the pages of the main database.
+// It is kept in step by the Page hooks and by the synchronizer, and rebuilt when created.
+// SQLite must be built with FTS5 (sqlite_fts5 build tag), else pages are searched with LIKE.
+
+const (
+	PAGE_SEARCH_LIMIT = 50
+
+	// Characters of text around the match in the snippets
+	PAGE_SEARCH_SNIPPET_TOKENS = 12
+	PAGE_SEARCH_SNIPPET_CHARS  = 80
+
+	// Private use characters, replaced by <mark> tags once the snippet is escaped
+	markStart = "\ue000"
+	markEnd   = "\ue001"
+)
+
+var pageSearchEnabled bool
+
+type PageSearchResult struct {
+	ID      string   `json:"id"`
+	Title   string   `json:"title"`   // HTML, the matched terms are wrapped in <mark>
+	Snippet string   `json:"snippet"` // HTML, the matched terms are wrapped in <mark>
+	Path    []string `json:"path"`    // Titles of the parent folders, from the root
+}
+
+// SetupPageSearch creates the full-text index of the pages, and fills it when it is new
+func SetupPageSearch(db *gorm.DB) error {
+	exists := db.Migrator().HasTable("page_search")
+
+	err := db.Exec(`CREATE VIRTUAL TABLE IF NOT EXISTS page_search USING fts5(
+		page_id UNINDEXED,
+		title,
+		content,
+		tokenize = 'unicode61 remove_diacritics 2'
+	)`).Error
+	if err != nil {
+		pageSearchEnabled = false
+		return err
+	}
+
+	pageSearchEnabled = true
+
+	if exists {
+		return nil
+	}
+
+	var pages []Page
+	db.Find(&pages)
+
+	for i := range pages {
+		if err := IndexPage(db, &pages[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IndexPage replaces the indexed text of a page
+func IndexPage(tx *gorm.DB, page *Page) error {
+	if !pageSearchEnabled || page.ID == "" {
+		return nil
+	}
+
+	if err := UnindexPage(tx, page.ID); err != nil {
+		return err
+	}
+
+	// Soft deleted pages are not searchable
+	if page.DeletedAt.Valid {
+		return nil
+	}
+
+	return tx.Exec(
+		"INSERT INTO page_search (page_id, title, content) VALUES (?, ?, ?)",
+		page.ID, page.Title, utils.HTMLToText(page.HtmlContent),
+	).Error
+}
+
+func UnindexPage(tx *gorm.DB, pageID string) error {
+	if !pageSearchEnabled {
+		return nil
+	}
+
+	return tx.Exec("DELETE FROM page_search WHERE page_id = ?", pageID).Error
+}
+
+// indexPageHook keeps the index in step with a saved page, a failure does not prevent the save
+func indexPageHook(tx *gorm.DB, page *Page) {
+	if err := IndexPage(tx.Session(&gorm.Session{NewDB: true}), page); err != nil {
+		slog.Error("Failed to index the page", "id", page.ID, "error", err)
+	}
+}
+
+// SearchPages returns the pages matching all the words of the query, best matches first.
+// The last word is matched as a prefix, so results show up while typing.
+func (r *PageRepository) SearchPages(query string) []PageSearchResult {
+	terms := strings.Fields(query)
+	if len(terms) == 0 {
+		return []PageSearchResult{}
+	}
+
+	var results []PageSearchResult
+	if pageSearchEnabled {
+		results = r.searchIndex(terms)
+	} else {
+		results = r.searchContent(terms)
+	}
+
+	r.setPaths(results)
+
+	return results
+}
+
+func (r *PageRepository) searchIndex(terms []string) []PageSearchResult {
+	// Terms are quoted, so the FTS5 query syntax is not interpreted
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	quoted[len(quoted)-1] += "*"
+
+	var rows []struct {
+		PageID  string
+		Title   string
+		Snippet string
+	}
+
+	r.db.Raw(`SELECT page_search.page_id AS page_id,
+			highlight(page_search, 1, ?, ?) AS title,
+			snippet(page_search, 2, ?, ?, '…', ?) AS snippet
+		FROM page_search
+		JOIN pages ON pages.id = page_search.page_id AND pages.deleted_at IS NULL
+		WHERE page_search MATCH ?
+		ORDER BY bm25(page_search, 0, 10.0, 1.0)
+		LIMIT ?`,
+		markStart, markEnd, markStart, markEnd, PAGE_SEARCH_SNIPPET_TOKENS,
+		strings.Join(quoted, " "), PAGE_SEARCH_LIMIT,
+	).Scan(&rows)
+
+	results := make([]PageSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, PageSearchResult{
+			ID:      row.PageID,
+			Title:   escapeMarked(row.Title),
+			Snippet: escapeMarked(strings.ReplaceAll(row.Snippet, "\n", " ")),
+		})
+	}
+
+	return results
+}
+
+// searchContent is used when SQLite is built without FTS5, the results are not ranked
+func (r *PageRepository) searchContent(terms []string) []PageSearchResult {
+	db := r.db.Model(&Page{})
+	for _, term := range terms {
+		like := "%" + term + "%"
+		db = db.Where("title LIKE ? OR html_content LIKE ?", like, like)
+	}
+
+	var pages []Page
+	db.Order("updated_at desc").Limit(PAGE_SEARCH_LIMIT).Find(&pages)
+
+	results := make([]PageSearchResult, 0, len(pages))
+	for _, page := range pages {
+		results = append(results, PageSearchResult{
+			ID:      page.ID,
+			Title:   html.EscapeString(page.Title),
+			Snippet: contentSnippet(utils.HTMLToText(page.HtmlContent), terms[0]),
+		})
+	}
+
+	return results
+}
+
+// setPaths fills the titles of the parent folders of the results
+func (r *PageRepository) setPaths(results []PageSearchResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	pages := make(map[string]Page)
+	for _, page := range r.GetPages() {
+		pages[page.ID] = page
+	}
+
+	for i := range results {
+		path := []string{}
+
+		// The visited pages guard against a cycle in the tree
+		visited := map[string]bool{results[i].ID: true}
+		parentID := pages[results[i].ID].ParentID
+
+		for parentID != nil && !visited[*parentID] {
+			parent, ok := pages[*parentID]
+			if !ok {
+				break
+			}
+
+			visited[parent.ID] = true
+			path = append([]string{parent.Title}, path...)
+			parentID = parent.ParentID
+		}
+
+		results[i].Path = path
+	}
+}
+
+// escapeMarked escapes the text and turns the match markers into <mark> tags
+func escapeMarked(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, markStart, "<mark>")
+	return strings.ReplaceAll(text, markEnd, "</mark>")
+}
+
+// contentSnippet returns the text around the first occurrence of the term
+func contentSnippet(text, term string) string {
+	lower := strings.ToLower(text)
+	if len(lower) != len(text) {
+		lower = text // Offsets would not match
+	}
+
+	index := strings.Index(lower, strings.ToLower(term))
+	if index < 0 {
+		return ""
+	}
+
+	start := max(0, index-PAGE_SEARCH_SNIPPET_CHARS/2)
+	end := min(len(text), index+len(term)+PAGE_SEARCH_SNIPPET_CHARS/2)
+
+	// Do not cut a character
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+
+	snippet := html.EscapeString(text[start:index]) +
+		"<mark>" + html.EscapeString(text[index:index+len(term)]) + "</mark>" +
+		html.EscapeString(text[index+len(term):end])
+
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+
+	return strings.ReplaceAll(snippet, "\n", " ")
+}
@@ -0,0 +1,221 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Deleted pages stay in the trash this number of days before being purged,
+// unless the config sets its own retention
+const DEFAULT_TRASH_RETENTION_DAYS = 30
+
+// GetTrashedPages returns the deleted pages and folders without their content, latest deleted first.
+// Pages deleted along with their folder are left out, they are restored and purged with it.
+func (r *PageRepository) GetTrashedPages() []Page {
+	var pages []Page
+
+	r.db.Unscoped().
+		Omit("html_content", "blocks").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at desc").
+		Find(&pages)
+
+	deletedAt := make(map[string]time.Time, len(pages))
+	for _, page := range pages {
+		deletedAt[page.ID] = page.DeletedAt.Time
+	}
+
+	roots := []Page{}
+	for _, page := range pages {
+		if page.ParentID != nil {
+			if parentDeletedAt, ok := deletedAt[*page.ParentID]; ok && parentDeletedAt.Equal(page.DeletedAt.Time) {
+				continue
+			}
+		}
+		roots = append(roots, page)
+	}
+
+	return roots
+}
+
+func (r *PageRepository) getTrashedPage(ID string) *Page {
+	var pages []Page
+
+	r.db.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", ID).
+		Limit(1).
+		Find(&pages)
+
+	if len(pages) == 0 {
+		return nil
+	}
+
+	return &pages[0]
+}
+
+func (r *PageRepository) getTrashedChildren(ID string) []Page {
+	var pages []Page
+
+	r.db.Unscoped().
+		Where("parent_id = ? AND deleted_at IS NOT NULL", ID).
+		Find(&pages)
+
+	return pages
+}
+
+// RestorePage takes a page out of the trash, in its original folder and order, with the
+// descendants deleted along with it. Its deleted parent folders are restored too,
+// and it goes to the root when they were purged.
+func (r *PageRepository) RestorePage(ID string) (*Page, error) {
+	page := r.getTrashedPage(ID)
+	if page == nil {
+		return nil, ErrPageNotFound
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		pages := NewPageRepository(tx)
+		deletedAt := page.DeletedAt.Time
+
+		if err := pages.restoreParents(page); err != nil {
+			return err
+		}
+
+		subtree := []Page{*page}
+		for i := 0; i < len(subtree); i++ {
+			if i > 0 {
+				if err := pages.restore(&subtree[i]); err != nil {
+					return err
+				}
+			}
+
+			for _, child := range pages.getTrashedChildren(subtree[i].ID) {
+				if child.DeletedAt.Time.Equal(deletedAt) {
+					subtree = append(subtree, child)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return page, err
+}
+
+// restoreParents restores a page and its deleted parent folders, from the root
+func (r *PageRepository) restoreParents(page *Page) error {
+	visited := map[string]bool{}
+	chain := []*Page{page}
+
+	for current := page; current.ParentID != nil && !visited[current.ID]; {
+		visited[current.ID] = true
+
+		var parents []Page
+		r.db.Unscoped().Where("id = ?", *current.ParentID).Limit(1).Find(&parents)
+
+		if len(parents) == 0 {
+			current.ParentID = nil
+			break
+		}
+		if !parents[0].DeletedAt.Valid {
+			break
+		}
+
+		current = &parents[0]
+		chain = append(chain, current)
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := r.restore(chain[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *PageRepository) restore(page *Page) error {
+	page.DeletedAt.Valid = false
+
+	// Saved as a whole, so the restore syncs as any other save
+	return r.db.Unscoped().Save(page).Error
+}
+
+// PurgePage permanently deletes a page of the trash, with its revisions and deleted descendants
+func (r *PageRepository) PurgePage(ID string) error {
+	page := r.getTrashedPage(ID)
+	if page == nil {
+		return ErrPageNotFound
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return NewPageRepository(tx).purgePage(page)
+	})
+}
+
+func (r *PageRepository) purgePage(page *Page) error {
+	for _, child := range r.getTrashedChildren(page.ID) {
+		if err := r.purgePage(&child); err != nil {
+			return err
+		}
+	}
+
+	var revisions []PageRevision
+	r.db.Unscoped().Where("page_id = ?", page.ID).Find(&revisions)
+
+	for i := range revisions {
+		if err := r.db.Unscoped().Delete(&revisions[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return r.db.Unscoped().Delete(page).Error
+}
+
+// EmptyTrash permanently deletes every page of the trash
+func (r *PageRepository) EmptyTrash() error {
+	_, err := r.purgePagesDeletedBefore(time.Now())
+	return err
+}
+
+// PurgeExpiredPages permanently deletes the pages in the trash for more than the given number of days
+func (r *PageRepository) PurgeExpiredPages(retentionDays int) (int, error) {
+	if retentionDays <= 0 {
+		return 0, nil
+	}
+
+	return r.purgePagesDeletedBefore(time.Now().AddDate(0, 0, -retentionDays))
+}
+
+func (r *PageRepository) purgePagesDeletedBefore(before time.Time) (int, error) {
+	var pages []Page
+
+	r.db.Unscoped().
+		Omit("html_content", "blocks").
+		Where("deleted_at IS NOT NULL AND deleted_at <= ?", before).
+		Find(&pages)
+
+	count := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		trash := NewPageRepository(tx)
+
+		for i := range pages {
+			// Already purged with its folder
+			if trash.getTrashedPage(pages[i].ID) == nil {
+				continue
+			}
+
+			if err := trash.purgePage(&pages[i]); err != nil {
+				return err
+			}
+			count++
+		}
+
+		return nil
+	})
+
+	return count, err
+}
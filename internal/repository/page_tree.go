@@ -0,0 +1,319 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrPageNotFound = errors.New("page not found")
+var ErrParentNotFolder = errors.New("the parent page is not a folder")
+var ErrPageMoveCycle = errors.New("a folder cannot be moved into itself or one of its subfolders")
+
+// Suffix of the title of a duplicated page
+const DUPLICATE_TITLE_SUFFIX = " (copy)"
+
+// getChildren returns the pages of a folder in their order, with their content so they can be saved
+func (r *PageRepository) getChildren(parentID *string) []Page {
+	var pages []Page
+
+	query := r.db.Order("`order`, created_at")
+	if parentID == nil {
+		query = query.Where("parent_id IS NULL")
+	} else {
+		query = query.Where("parent_id = ?", *parentID)
+	}
+	query.Find(&pages)
+
+	return pages
+}
+
+// getSubtree returns a page followed by all its descendants, parents before their children
+func (r *PageRepository) getSubtree(page Page) []Page {
+	subtree := []Page{page}
+	visited := map[string]bool{page.ID: true}
+
+	for i := 0; i < len(subtree); i++ {
+		for _, child := range r.getChildren(&subtree[i].ID) {
+			if !visited[child.ID] {
+				visited[child.ID] = true
+				subtree = append(subtree, child)
+			}
+		}
+	}
+
+	return subtree
+}
+
+// deleteSubtree soft deletes a page with its descendants.
+// They share the same deletion time, so they can be restored together.
+func (r *PageRepository) deleteSubtree(page Page) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		deletedAt := time.Now()
+		deleteTx := tx.Session(&gorm.Session{NowFunc: func() time.Time { return deletedAt }})
+
+		subtree := NewPageRepository(tx).getSubtree(page)
+
+		// Children first, so no live page points to a deleted folder
+		for i := len(subtree) - 1; i >= 0; i-- {
+			if err := deleteTx.Delete(&subtree[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// isDescendant tells whether a page is the given folder or one of its descendants
+func (r *PageRepository) isDescendant(pageID string, folderID string) bool {
+	visited := map[string]bool{}
+	ID := &pageID
+
+	for ID != nil && !visited[*ID] {
+		if *ID == folderID {
+			return true
+		}
+		visited[*ID] = true
+
+		var pages []Page
+		r.db.Unscoped().Select("id", "parent_id").Where("id = ?", *ID).Limit(1).Find(&pages)
+		if len(pages) == 0 {
+			return false
+		}
+		ID = pages[0].ParentID
+	}
+
+	return false
+}
+
+// checkParent makes sure a page can be moved into the given folder
+func (r *PageRepository) checkParent(ID string, parentID *string) error {
+	if parentID == nil {
+		return nil
+	}
+
+	parent := r.GetPage(*parentID)
+	if parent.ID == "" {
+		return ErrPageNotFound
+	}
+	if !parent.IsFolder {
+		return ErrParentNotFolder
+	}
+	if r.isDescendant(*parentID, ID) {
+		return ErrPageMoveCycle
+	}
+
+	return nil
+}
+
+// MovePage moves a page with its descendants into a folder, or to the root without parentID.
+// The page takes the given position among its new siblings, and both folders are re-ordered.
+func (r *PageRepository) MovePage(ID string, parentID *string, position int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		pages := NewPageRepository(tx)
+
+		page := pages.GetPage(ID)
+		if page.ID == "" {
+			return ErrPageNotFound
+		}
+
+		if err := pages.checkParent(ID, parentID); err != nil {
+			return err
+		}
+
+		oldParentID := page.ParentID
+
+		var siblings []Page
+		for _, sibling := range pages.getChildren(parentID) {
+			if sibling.ID != ID {
+				siblings = append(siblings, sibling)
+			}
+		}
+
+		position = max(0, min(position, len(siblings)))
+		page.ParentID = parentID
+		siblings = append(siblings[:position], append([]Page{*page}, siblings[position:]...)...)
+
+		if err := pages.reorder(siblings, ID); err != nil {
+			return err
+		}
+
+		// Close the gap left in the previous folder
+		if !sameParent(oldParentID, parentID) {
+			return pages.reorder(pages.getChildren(oldParentID), "")
+		}
+
+		return nil
+	})
+}
+
+// reorder numbers the pages of a folder from their position, the changed page is always saved
+func (r *PageRepository) reorder(siblings []Page, changedID string) error {
+	for i := range siblings {
+		if siblings[i].ID != changedID && siblings[i].Order == i {
+			continue
+		}
+
+		siblings[i].Order = i
+		if err := r.db.Model(&siblings[i]).Updates(MapUpdate{
+			"order":    i,
+			"ParentID": siblings[i].ParentID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameParent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// DuplicatePage copies a page, or a folder with all its descendants, under new IDs.
+// The copy is placed right after the original.
+func (r *PageRepository) DuplicatePage(ID string) (*Page, error) {
+	var duplicate *Page
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		pages := NewPageRepository(tx)
+
+		page := pages.GetPage(ID)
+		if page.ID == "" {
+			return ErrPageNotFound
+		}
+
+		// Make room after the original
+		for _, sibling := range pages.getChildren(page.ParentID) {
+			if sibling.ID == page.ID || sibling.Order <= page.Order {
+				continue
+			}
+
+			sibling.Order++
+			if err := tx.Model(&sibling).Update("order", sibling.Order).Error; err != nil {
+				return err
+			}
+		}
+
+		// Original IDs mapped to the IDs of their copies, parents are copied first
+		copies := map[string]string{}
+
+		for i, original := range pages.getSubtree(*page) {
+			duplicated := Page{
+				Title:       original.Title,
+				HtmlContent: original.HtmlContent,
+				Blocks:      original.Blocks,
+				IsFolder:    original.IsFolder,
+				Expanded:    original.Expanded,
+				Order:       original.Order,
+				ParentID:    original.ParentID,
+			}
+
+			if i == 0 {
+				duplicated.Title += DUPLICATE_TITLE_SUFFIX
+				duplicated.Order++
+			} else {
+				parentID := copies[*original.ParentID]
+				duplicated.ParentID = &parentID
+			}
+
+			if err := tx.Create(&duplicated).Error; err != nil {
+				return err
+			}
+			NewPageRevisionRepository(tx).CaptureRevision(&duplicated)
+
+			copies[original.ID] = duplicated.ID
+			if i == 0 {
+				duplicate = &duplicated
+			}
+		}
+
+		return nil
+	})
+
+	return duplicate, err
+}
+
+// GetPageTree returns a page with all its descendants loaded as children, content included
+func (r *PageRepository) GetPageTree(ID string) (*Page, error) {
+	page := r.GetPage(ID)
+	if page.ID == "" {
+		return nil, ErrPageNotFound
+	}
+
+	r.loadChildren(page, map[string]bool{page.ID: true})
+
+	return page, nil
+}
+
+func (r *PageRepository) loadChildren(page *Page, visited map[string]bool) {
+	page.Children = nil
+
+	for _, child := range r.getChildren(&page.ID) {
+		if visited[child.ID] {
+			continue
+		}
+		visited[child.ID] = true
+
+		r.loadChildren(&child, visited)
+		page.Children = append(page.Children, child)
+	}
+}
+
+// ImportPages creates pages with their children at the end of a folder, or of the root without parentID.
+// The created pages are returned with their new IDs.
+func (r *PageRepository) ImportPages(parentID *string, pages []Page) ([]Page, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		tree := NewPageRepository(tx)
+
+		if parentID != nil {
+			parent := tree.GetPage(*parentID)
+			if parent.ID == "" {
+				return ErrPageNotFound
+			}
+			if !parent.IsFolder {
+				return ErrParentNotFolder
+			}
+		}
+
+		order := 0
+		for _, sibling := range tree.getChildren(parentID) {
+			order = max(order, sibling.Order+1)
+		}
+
+		return tree.createPages(parentID, pages, order)
+	})
+
+	return pages, err
+}
+
+func (r *PageRepository) createPages(parentID *string, pages []Page, order int) error {
+	for i := range pages {
+		page := &pages[i]
+
+		page.ID = ""
+		page.ParentID = parentID
+		page.Order = order + i
+
+		// Children are created below, once the page has an ID
+		if err := r.db.Omit(clause.Associations).Create(page).Error; err != nil {
+			return err
+		}
+		NewPageRevisionRepository(r.db).CaptureRevision(page)
+
+		if err := r.createPages(&page.ID, page.Children, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@
 package repository
 
 import (
+	"log/slog"
+
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
@@ -26,15 +28,20 @@ type Page struct {
 
 // Hooks
 func (n *Page) AfterCreate(tx *gorm.DB) error {
+	indexPageHook(tx, n)
 	return logChange(tx, n, OPERATION_SAVE)
 }
 
 func (n *Page) AfterUpdate(tx *gorm.DB) error {
+	indexPageHook(tx, n)
 	return logChange(tx, n, OPERATION_SAVE)
 }
 
 func (n *Page) AfterDelete(tx *gorm.DB) error {
-	return logChange(tx, n, OPERATION_DELETE)
+	if err := UnindexPage(tx.Session(&gorm.Session{NewDB: true}), n.ID); err != nil {
+		slog.Error("Failed to remove the page from the index", "id", n.ID, "error", err)
+	}
+	return logChange(tx, n, deleteOperation(tx))
 }
 
 type PageRepository struct {
@@ -63,23 +70,41 @@ func (r *PageRepository) GetPage(ID string) *Page {
 	return &page
 }
 
-func (r *PageRepository) UpdatePageOrder(ID string, ParentID *string, order int) {
-	r.db.Model(r.GetPage(ID)).
+func (r *PageRepository) UpdatePageOrder(ID string, ParentID *string, order int) error {
+	if err := r.checkParent(ID, ParentID); err != nil {
+		return err
+	}
+
+	return r.db.Model(r.GetPage(ID)).
 		Where("id = ?", ID).
 		Updates(MapUpdate{
 			"order":    order,
 			"ParentID": ParentID,
-		})
+		}).Error
 }
 
+// SavePage saves a page and captures a revision of its content
 func (r *PageRepository) SavePage(page *Page) *Page {
+	revisions := NewPageRevisionRepository(r.db)
+
+	// Pages saved before revisions existed keep their stored content as the first revision
+	baseline := page.ID != "" && revisions.getLatestRevision(page.ID) == nil
+	if baseline {
+		revisions.captureRevision(r.GetPage(page.ID), false)
+	}
+
 	r.db.Save(page)
+	revisions.captureRevision(page, !baseline)
+
 	return page
 }
 
-func (r *PageRepository) DeletePage(ID string) {
-	var page Page
+// DeletePage moves a page to the trash, with all its descendants
+func (r *PageRepository) DeletePage(ID string) error {
+	page := r.GetPage(ID)
+	if page.ID == "" {
+		return ErrPageNotFound
+	}
 
-	r.db.First(&page, "id = ?", ID)
-	r.db.Delete(&page)
+	return r.deleteSubtree(*page)
 }
@@ -0,0 +1,75 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// !SYNCED MODEL
+
+// ReadProgress is the reading position of a page on a device. Each device only writes its own row,
+// so the progress of a device is never overwritten by another one, and the latest row is resumed.
+type ReadProgress struct {
+	BaseUUIDModel
+
+	PageID   string    `json:"page_id" gorm:"uniqueIndex:idx_read_progress_page_device"`
+	DeviceID string    `json:"device_id" gorm:"uniqueIndex:idx_read_progress_page_device"`
+	Progress int       `json:"progress"`
+	Total    int       `json:"total"`
+	ReadAt   time.Time `json:"read_at"` // When the reading position last moved
+}
+
+// Hooks
+func (n *ReadProgress) AfterCreate(tx *gorm.DB) error {
+	return logChange(tx, n, OPERATION_SAVE)
+}
+
+func (n *ReadProgress) AfterUpdate(tx *gorm.DB) error {
+	return logChange(tx, n, OPERATION_SAVE)
+}
+
+func (n *ReadProgress) AfterDelete(tx *gorm.DB) error {
+	return logChange(tx, n, deleteOperation(tx))
+}
+
+type ReadProgressRepository struct {
+	BaseRepository
+}
+
+func NewReadProgressRepository(db *gorm.DB) *ReadProgressRepository {
+	return &ReadProgressRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetReadProgress returns the latest progress of the page on any device, nil if it was never read
+func (r *ReadProgressRepository) GetReadProgress(pageID string) *ReadProgress {
+	var progress ReadProgress
+
+	if err := r.db.Where("page_id = ?", pageID).Order("read_at desc").First(&progress).Error; err != nil {
+		return nil
+	}
+
+	return &progress
+}
+
+// SaveReadProgress updates the progress of the page on the device
+func (r *ReadProgressRepository) SaveReadProgress(pageID, deviceID string, progress, total int) *ReadProgress {
+	var readProgress ReadProgress
+
+	r.db.Where("page_id = ? AND device_id = ?", pageID, deviceID).First(&readProgress)
+
+	readProgress.PageID = pageID
+	readProgress.DeviceID = deviceID
+	readProgress.Progress = progress
+	readProgress.Total = total
+	readProgress.ReadAt = time.Now()
+
+	r.db.Save(&readProgress)
+
+	return &readProgress
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// !SYNCED MODEL
+
+// ReadingSession is the practice history of a page, kept for every recording session
+type ReadingSession struct {
+	BaseUUIDModel
+
+	PageID         string     `json:"page_id" gorm:"index"`
+	StartedAt      time.Time  `json:"started_at"`
+	EndedAt        *time.Time `json:"ended_at"`
+	Duration       int64      `json:"duration"` // ms
+	Language       string     `json:"language"`
+	Provider       string     `json:"provider"`         // Transcriber source
+	WordsCovered   int        `json:"words_covered"`    // Script words aligned with the transcripts
+	WordsPerMinute float64    `json:"words_per_minute"` // Average over the speaking time
+	ErrorCount     int        `json:"error_count"`      // Sentences skipped or paraphrased, and insertions
+}
+
+// Hooks
+func (n *ReadingSession) AfterCreate(tx *gorm.DB) error {
+	return logChange(tx, n, OPERATION_SAVE)
+}
+
+func (n *ReadingSession) AfterUpdate(tx *gorm.DB) error {
+	return logChange(tx, n, OPERATION_SAVE)
+}
+
+func (n *ReadingSession) AfterDelete(tx *gorm.DB) error {
+	return logChange(tx, n, deleteOperation(tx))
+}
+
+// Number of the latest sessions returned in the trend of the page stats
+const READING_TREND_SESSIONS = 10
+
+type ReadingStats struct {
+	PageID                string           `json:"page_id"`
+	TimesRehearsed        int              `json:"times_rehearsed"`
+	TotalDuration         int64            `json:"total_duration"`   // ms
+	AverageDuration       float64          `json:"average_duration"` // ms
+	AverageWordsPerMinute float64          `json:"average_words_per_minute"`
+	AverageErrorCount     float64          `json:"average_error_count"`
+	LastReadAt            *time.Time       `json:"last_read_at"`
+	Trend                 []ReadingSession `json:"trend"` // Latest sessions, oldest first
+}
+
+type ReadingSessionRepository struct {
+	BaseRepository
+}
+
+func NewReadingSessionRepository(db *gorm.DB) *ReadingSessionRepository {
+	return &ReadingSessionRepository{
+		BaseRepository: BaseRepository{db: db},
+	}
+}
+
+// GetReadingSessions returns the finished sessions of a page, latest first
+func (r *ReadingSessionRepository) GetReadingSessions(pageID string) []ReadingSession {
+	var sessions []ReadingSession
+
+	r.db.Where("page_id = ? AND ended_at IS NOT NULL", pageID).
+		Order("started_at desc").
+		Find(&sessions)
+
+	return sessions
+}
+
+// GetReadingStats returns the stats of every page read at least once, latest read first
+func (r *ReadingSessionRepository) GetReadingStats() []ReadingStats {
+	var pageIDs []string
+
+	r.db.Model(&ReadingSession{}).
+		Where("ended_at IS NOT NULL").
+		Group("page_id").
+		Order("MAX(started_at) desc").
+		Pluck("page_id", &pageIDs)
+
+	stats := make([]ReadingStats, 0, len(pageIDs))
+	for _, pageID := range pageIDs {
+		stats = append(stats, r.GetPageReadingStats(pageID))
+	}
+
+	return stats
+}
+
+// GetPageReadingStats aggregates the finished sessions of a page, with the latest sessions as trend
+func (r *ReadingSessionRepository) GetPageReadingStats(pageID string) ReadingStats {
+	sessions := r.GetReadingSessions(pageID)
+	stats := ReadingStats{PageID: pageID, Trend: []ReadingSession{}}
+
+	var wpmTotal float64
+	var wpmCount, errorTotal int
+
+	for _, session := range sessions {
+		stats.TimesRehearsed++
+		stats.TotalDuration += session.Duration
+		errorTotal += session.ErrorCount
+
+		// Sessions without script alignment have no reading pace
+		if session.WordsPerMinute > 0 {
+			wpmTotal += session.WordsPerMinute
+			wpmCount++
+		}
+	}
+
+	if stats.TimesRehearsed == 0 {
+		return stats
+	}
+
+	stats.AverageDuration = float64(stats.TotalDuration) / float64(stats.TimesRehearsed)
+	stats.AverageErrorCount = float64(errorTotal) / float64(stats.TimesRehearsed)
+	if wpmCount > 0 {
+		stats.AverageWordsPerMinute = wpmTotal / float64(wpmCount)
+	}
+	stats.LastReadAt = &sessions[0].StartedAt
+
+	for i := min(len(sessions), READING_TREND_SESSIONS) - 1; i >= 0; i-- {
+		stats.Trend = append(stats.Trend, sessions[i])
+	}
+
+	return stats
+}
+
+func (r *ReadingSessionRepository) SaveReadingSession(session *ReadingSession) *ReadingSession {
+	r.db.Save(session)
+	return session
+}
+
+func (r *ReadingSessionRepository) DeleteReadingSession(ID string) {
+	var session ReadingSession
+
+	r.db.First(&session, "id = ?", ID)
+	r.db.Delete(&session)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package repository
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// UNSYNCED MODEL
+
+// Audio files are stored on the local machine, so their sessions are not synced.
+// A session is saved even when its audio is not recorded.
+type RecordingSession struct {
+	gorm.Model
+	PageID     string `gorm:"index"`
+	DeviceName string
+	Language   string
+	StartedAt  time.Time
+	EndedAt    *time.Time
+	Duration   int64  // ms
+	FileName   string // Relative to the recordings directory, empty without audio
+	FileSize   int64
+	SampleRate uint32
+	Channels   uint32
+	Coverage   datatypes.JSON // alignment.CoverageReport, when the script was aligned
+	Transcript datatypes.JSON // []captions.Segment, timed from the start of the recording
+}
+
+type RecordingSessionRepository struct {
+	BaseRepository
+}
+
+func NewRecordingSessionRepository(unSyncedDB *gorm.DB) *RecordingSessionRepository {
+	return &RecordingSessionRepository{
+		BaseRepository: BaseRepository{db: unSyncedDB},
+	}
+}
+
+func (r *RecordingSessionRepository) GetRecordingSessions(pageID string) []RecordingSession {
+	var sessions []RecordingSession
+
+	r.db.Where("page_id = ?", pageID).Order("started_at desc").Find(&sessions)
+
+	return sessions
+}
+
+func (r *RecordingSessionRepository) GetRecordingSession(ID uint) *RecordingSession {
+	var session RecordingSession
+
+	if err := r.db.First(&session, ID).Error; err != nil {
+		return nil
+	}
+
+	return &session
+}
+
+func (r *RecordingSessionRepository) SaveRecordingSession(session *RecordingSession) *RecordingSession {
+	r.db.Save(session)
+	return session
+}
+
+func (r *RecordingSessionRepository) DeleteRecordingSession(ID uint) {
+	r.db.Unscoped().Delete(&RecordingSession{}, ID)
+}
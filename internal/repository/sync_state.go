@@ -6,6 +6,7 @@ package repository
 import (
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
@@ -14,6 +15,7 @@ import (
 type SyncState struct {
 	gorm.Model
 	SyncTimeOffset time.Time
+	DeviceID       string // Identifies this installation in the synced data
 }
 
 type SyncStateRepository struct {
@@ -52,3 +54,15 @@ func (r *SyncStateRepository) SaveSyncState(syncTimeOffset time.Time) *SyncState
 
 	return syncState
 }
+
+// GetDeviceID returns the identifier of this installation, created on first use
+func (r *SyncStateRepository) GetDeviceID() string {
+	syncState := r.GetSyncState()
+
+	if syncState.DeviceID == "" {
+		syncState.DeviceID = uuid.New().String()
+		r.db.Save(syncState)
+	}
+
+	return syncState.DeviceID
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+var (
+	breakTagRegex = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|div|li|h[1-6]|blockquote|pre|tr)>`)
+	tagRegex      = regexp.MustCompile(`<[^>]*>`)
+)
+
+// HTMLToText returns the text of an HTML content, with a line break after each block
+func HTMLToText(content string) string {
+	text := breakTagRegex.ReplaceAllString(content, "\n")
+	text = tagRegex.ReplaceAllString(text, "")
+
+	return strings.TrimSpace(html.UnescapeString(text))
+}
+
+// ParseHTMLFragment parses an HTML content as the children of a body element
+func ParseHTMLFragment(content string) *nethtml.Node {
+	body := &nethtml.Node{Type: nethtml.ElementNode, Data: "body", DataAtom: atom.Body}
+
+	nodes, err := nethtml.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		return body
+	}
+
+	for _, node := range nodes {
+		body.AppendChild(node)
+	}
+
+	return body
+}
+
+// HTMLAttribute returns the value of an attribute of a node, empty when it is missing
+func HTMLAttribute(n *nethtml.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// HTMLTextContent returns the text of a node, line breaks included
+func HTMLTextContent(n *nethtml.Node) string {
+	if n.Type == nethtml.TextNode {
+		return n.Data
+	}
+	if n.DataAtom == atom.Br {
+		return "\n"
+	}
+
+	var builder strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(HTMLTextContent(child))
+	}
+	return builder.String()
+}
+
+// HTMLCodeLanguage reads the language of a code block from its "language-" class
+func HTMLCodeLanguage(pre *nethtml.Node) string {
+	nodes := []*nethtml.Node{pre}
+	if pre.FirstChild != nil && pre.FirstChild.DataAtom == atom.Code {
+		nodes = append(nodes, pre.FirstChild)
+	}
+
+	for _, node := range nodes {
+		for _, class := range strings.Fields(HTMLAttribute(node, "class")) {
+			if language, ok := strings.CutPrefix(class, "language-"); ok {
+				return language
+			}
+		}
+	}
+
+	return ""
+}
+
+// CollapseSpaces collapses the whitespace of a text as browsers render it
+func CollapseSpaces(text string) string {
+	var builder strings.Builder
+	space := false
+
+	for _, r := range text {
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f' {
+			space = true
+			continue
+		}
+		if space {
+			builder.WriteByte(' ')
+			space = false
+		}
+		builder.WriteRune(r)
+	}
+	if space {
+		builder.WriteByte(' ')
+	}
+
+	return builder.String()
+}
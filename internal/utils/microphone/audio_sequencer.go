@@ -6,6 +6,7 @@ package microphone
 import (
 	"bytes"
 	"encoding/binary"
+	"fmt"
 	"log/slog"
 	"math"
 	"sync"
@@ -21,6 +22,27 @@ const (
 	DEFAULT_SAMPLE_RATE = 16000
 
 	DEFAULT_CHANNELS = 1
+
+	DEFAULT_NOISE_THRESHOLD = -50
+
+	DEFAULT_TRIGGER_DECIBELS = -40
+
+	DEFAULT_PRE_ROLL_TIME = 300 // ms
+
+	DEFAULT_POST_ROLL_TIME = 200 // ms
+
+	DEFAULT_MAX_CHUNK_TIME = 1000 * 15 // 15 seconds
+
+	DEFAULT_OVERLAP_TIME = 500 // ms
+
+	// A forced split happens at the quietest frame of the chunk tail of this duration
+	SPLIT_SEARCH_TIME = 2000 // ms
+
+	// Duration of the frames compared when searching for a split point
+	SPLIT_FRAME_TIME = 20 // ms
+
+	// Minimum interval between two audio level callbacks (10 Hz)
+	LEVEL_INTERVAL = 100 * time.Millisecond
 )
 
 type NoiseConfig struct {
@@ -30,22 +52,67 @@ type NoiseConfig struct {
 	TriggerDecibels float64 // Decibel value to trigger the callback (-30 default)
 	NoiseThreshold  float64 // Noise detection sensitivity
 	MaxBlankTime    int64   // Maximum time to consider a blank (ms) - 600 default
+	PreRollTime     int64   // Audio kept before the speech onset (ms) - 300 default
+	PostRollTime    int64   // Audio kept after the last voiced frame (ms) - 200 default
+	MaxChunkTime    int64   // Maximum chunk duration before a forced split (ms) - 15000 default
+	OverlapTime     int64   // Audio shared by two chunks of a forced split (ms) - 500 default
+	VoiceDetector   string  // Voice activity detector (decibel default)
+
+	// Pre-processing, before the voice detection and the chunks
+	Processing ProcessingConfig
 
 	// Audio stream
 	SampleRate uint32 // Sample rate (16000 default)
 	Channels   uint32 // Number of channels (1 default)
 
-	OnSequential func([]byte)           // Callback when silence is detected
+	// Multi-channel interfaces
+	CaptureChannels uint32   // Channels captured from the device, 0 lets the backend downmix to Channels
+	InputChannels   []uint32 // 0-based captured channels mixed for the transcription, empty mixes all
+
+	OnSequential func(AudioChunk)       // Callback when silence is detected
 	OnStop       func(autoStopped bool) // Callback when recording is stopped
+	OnLevel      func(AudioLevel)       // Throttled callback with the input level
+	OnFrames     func([]byte)           // Synchronous callback with every received frame
+	OnDeviceLost func()                 // Callback when the capture device stops delivering frames
+}
+
+type AudioChunk struct {
+	Data     []byte        // S16 samples
+	Offset   time.Duration // Start of the chunk in the recording
+	Duration time.Duration // Length of the chunk
+	Overlap  time.Duration // Audio at the start shared with the previous chunk
+}
+
+type AudioLevel struct {
+	PeakDecibels     float64 // Peak level of the last frame (dBFS)
+	RMSDecibels      float64 // RMS level of the last frame (dBFS)
+	InSpeech         bool    // The sequencer is inside a speech segment
+	BufferedDuration int64   // Duration of the audio waiting to be sequenced (ms)
 }
 
 type AudioSequencer struct {
-	ctx           *malgo.AllocatedContext
-	device        *malgo.Device
+	source        AudioSource
+	lastFrameTime time.Time
 	config        NoiseConfig
+	detector      VoiceActivityDetector
+	processing    *ProcessingChain
 	lastNoiseTime time.Time
+
+	// Segmentation state
+	currentBuffer   []byte
+	preRoll         *RingBuffer
+	lastVoiceOffset int   // End of the last voiced frame in currentBuffer
+	chunkOverlap    int   // Start of currentBuffer shared with the previous chunk
+	streamSize      int64 // Bytes received since the start, currentBuffer ends there while in speech
+	lastVoiceStream int64 // Stream position of the end of the last voiced frame
+
+	// Chunks whose OnSequential callback has not returned yet
+	pendingChunks sync.WaitGroup
+
 	isRecording   bool
+	isPaused      bool
 	inSpeechModal bool
+	lastLevelTime time.Time
 	mu            sync.Mutex
 }
 
@@ -53,15 +120,21 @@ type MicInputDevice struct {
 	Name      string
 	IsDefault uint32
 	ID        malgo.DeviceID
+	Channels  uint32 // Maximum native input channels, 0 if unknown
 }
 
 func NewAudioSequencer() *AudioSequencer {
 	config := NoiseConfig{
 		MinDecibels:     -100,
 		MaxDecibels:     0,
-		TriggerDecibels: -40,
-		NoiseThreshold:  -50,
+		TriggerDecibels: DEFAULT_TRIGGER_DECIBELS,
+		NoiseThreshold:  DEFAULT_NOISE_THRESHOLD,
 		MaxBlankTime:    500,
+		PreRollTime:     DEFAULT_PRE_ROLL_TIME,
+		PostRollTime:    DEFAULT_POST_ROLL_TIME,
+		MaxChunkTime:    DEFAULT_MAX_CHUNK_TIME,
+		OverlapTime:     DEFAULT_OVERLAP_TIME,
+		VoiceDetector:   DEFAULT_VAD,
 
 		SampleRate: DEFAULT_SAMPLE_RATE,
 		Channels:   DEFAULT_CHANNELS,
@@ -84,6 +157,9 @@ func (ar *AudioSequencer) GetDeviceConfig() malgo.DeviceConfig {
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
 	deviceConfig.Capture.Format = malgo.FormatS16
 	deviceConfig.Capture.Channels = ar.config.Channels
+	if ar.config.CaptureChannels > 0 {
+		deviceConfig.Capture.Channels = ar.config.CaptureChannels
+	}
 	deviceConfig.SampleRate = ar.config.SampleRate
 	deviceConfig.Alsa.NoMMap = 1
 
@@ -111,10 +187,22 @@ func (ar *AudioSequencer) GetMicInputDevices() ([]MicInputDevice, error) {
 	var micInputDevices []MicInputDevice
 
 	for _, device := range devices {
+		// The enumeration does not always include the native formats
+		formats := device.Formats
+		if info, err := ctx.DeviceInfo(malgo.Capture, device.ID, malgo.Shared); err == nil {
+			formats = info.Formats
+		}
+
+		var channels uint32
+		for _, format := range formats {
+			channels = max(channels, format.Channels)
+		}
+
 		micInputDevices = append(micInputDevices, MicInputDevice{
 			ID:        device.ID,
 			Name:      device.Name(),
 			IsDefault: device.IsDefault,
+			Channels:  channels,
 		})
 	}
 
@@ -122,7 +210,27 @@ func (ar *AudioSequencer) GetMicInputDevices() ([]MicInputDevice, error) {
 
 }
 
-// This function will block until the recording is stopped
+func (ar *AudioSequencer) GetMicInputDevice(micInputDeviceID []byte) (*MicInputDevice, error) {
+	devices, err := ar.GetMicInputDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceID, err := ar.convertToDeviceID(micInputDeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, device := range devices {
+		if device.ID == deviceID {
+			return &device, nil
+		}
+	}
+
+	return nil, fmt.Errorf("microphone input device not found")
+}
+
+// Start records the microphone input device
 func (ar *AudioSequencer) Start(micInputDeviceID []byte) error {
 	if ar.isRecording {
 		return nil
@@ -133,74 +241,82 @@ func (ar *AudioSequencer) Start(micInputDeviceID []byte) error {
 		return err
 	}
 
-	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	source, err := ar.newMalgoSource(micDeviceID)
 	if err != nil {
 		return err
 	}
 
-	ar.ctx = ctx
-
-	var currentBuffer []byte
-	ar.lastNoiseTime = time.Now()
-	ar.inSpeechModal = false
-
-	onRecvFrames := func(pSample2, pSample []byte, framecount uint32) {
-		ar.mu.Lock()
-		defer ar.mu.Unlock()
-
-		if !ar.isRecording {
-			return
-		}
-
-		// Detect noise in the current frame
-		hasNoise, db := ar.detectNoise(pSample)
+	return ar.StartSource(source)
+}
 
-		if hasNoise {
-			if !ar.inSpeechModal && db >= ar.config.TriggerDecibels {
-				ar.inSpeechModal = true
-			}
+// newMalgoSource creates the source of a capture device, mixing the selected
+// channels when more channels than Channels are captured
+func (ar *AudioSequencer) newMalgoSource(micDeviceID malgo.DeviceID) (*MalgoSource, error) {
+	var mixer *ChannelMixer
 
-			currentBuffer = append(currentBuffer, pSample...)
-			ar.lastNoiseTime = time.Now()
-		} else {
-			// Check if silence duration exceeds MaxBlankTime
-			if ar.inSpeechModal && time.Since(ar.lastNoiseTime).Milliseconds() > ar.config.MaxBlankTime && len(currentBuffer) > 0 {
-				ar.inSpeechModal = false
-
-				// Call the callback with the recorded buffer
-				if ar.config.OnSequential != nil {
-					// Make a copy of the buffer
-					bufferCopy := make([]byte, len(currentBuffer))
-					copy(bufferCopy, currentBuffer)
-					go ar.config.OnSequential(bufferCopy)
-				}
-				// Clear the buffer
-				currentBuffer = currentBuffer[:0]
-
-				ar.lastNoiseTime = time.Now()
-			} else {
-				currentBuffer = append(currentBuffer, pSample...)
-			}
+	if ar.config.CaptureChannels > 0 {
+		var err error
+		mixer, err = NewChannelMixer(ar.config.CaptureChannels, ar.config.Channels, ar.config.InputChannels)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	deviceConfig := ar.GetDeviceConfig()
-	deviceConfig.Capture.DeviceID = micDeviceID.Pointer()
+	return NewMalgoSource(micDeviceID, ar.GetDeviceConfig(), mixer), nil
+}
 
-	deviceCallbacks := malgo.DeviceCallbacks{Data: onRecvFrames}
+// StartSource records any audio source delivering frames in the NoiseConfig format
+func (ar *AudioSequencer) StartSource(source AudioSource) error {
+	if ar.isRecording {
+		return nil
+	}
 
-	device, err := malgo.InitDevice(ctx.Context, deviceConfig, deviceCallbacks)
+	detector, err := NewVoiceActivityDetector(ar.config.VoiceDetector, ar.config)
 	if err != nil {
 		return err
 	}
 
-	ar.device = device
+	ar.mu.Lock()
+	ar.detector = detector
+	ar.processing = NewProcessingChain(ar.config.Processing, ar.config.SampleRate, ar.config.Channels)
+	ar.streamSize = 0
+	ar.resetSequence()
+	ar.source = source
 	ar.isRecording = true
+	ar.lastFrameTime = time.Now()
+	ar.mu.Unlock()
+
+	if err := source.Start(ar.receiveFrames); err != nil {
+		ar.isRecording = false
+		ar.source = nil
+		return err
+	}
 
 	// Start auto stop
 	go ar.autoStop()
 
-	return device.Start()
+	// Watch for the source to be lost or ended
+	go ar.monitorSource()
+
+	return nil
+}
+
+func (ar *AudioSequencer) receiveFrames(samples []byte) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	ar.lastFrameTime = time.Now()
+
+	if !ar.isRecording || ar.isPaused {
+		return
+	}
+
+	// The session audio keeps the raw input
+	if ar.config.OnFrames != nil {
+		ar.config.OnFrames(samples)
+	}
+
+	ar.processFrame(ar.processing.Process(samples))
 }
 
 func (ar *AudioSequencer) autoStop() {
@@ -210,6 +326,11 @@ func (ar *AudioSequencer) autoStop() {
 	defer ticker.Stop()
 
 	for range ticker.C {
+		// The silence timer is stopped while paused
+		if ar.Paused() {
+			continue
+		}
+
 		if !ar.inSpeechModal && time.Since(ar.lastNoiseTime).Milliseconds() > MAX_SILENCE_TIME {
 			ar.Stop(true)
 			slog.Debug("Auto stop recording after silence", "duration_ms", MAX_SILENCE_TIME)
@@ -220,30 +341,147 @@ func (ar *AudioSequencer) autoStop() {
 	}
 }
 
+// Pause keeps the device open but ignores the received frames.
+// The current speech chunk is flushed, so nothing said before the pause is lost.
+func (ar *AudioSequencer) Pause() error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if !ar.isRecording {
+		return fmt.Errorf("not recording")
+	}
+
+	if ar.isPaused {
+		return nil
+	}
+
+	if ar.inSpeechModal {
+		ar.flush()
+	}
+
+	ar.isPaused = true
+
+	return nil
+}
+
+// Resume restarts the segmentation after a Pause
+func (ar *AudioSequencer) Resume() error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if !ar.isRecording {
+		return fmt.Errorf("not recording")
+	}
+
+	if !ar.isPaused {
+		return nil
+	}
+
+	// The stream position is kept, chunk offsets stay relative to the recorded audio
+	ar.resetSequence()
+	ar.isPaused = false
+
+	return nil
+}
+
+func (ar *AudioSequencer) Paused() bool {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	return ar.isPaused
+}
+
+// Stop closes the source and sends the speech chunk in progress.
+// OnStop is called once the OnSequential callbacks of every chunk have returned.
 func (ar *AudioSequencer) Stop(autoStopped bool) {
 	ar.isRecording = false
 
-	if ar.device != nil {
-		ar.device.Uninit()
-		ar.device = nil
+	if ar.source != nil {
+		ar.source.Stop()
+		ar.source = nil
 	}
 
-	if ar.ctx != nil {
-		ar.ctx.Uninit()
-		ar.ctx.Free()
-		ar.ctx = nil
+	ar.mu.Lock()
+	if ar.inSpeechModal {
+		ar.flush()
 	}
+	ar.isPaused = false
+	ar.mu.Unlock()
+
+	ar.pendingChunks.Wait()
 
 	if ar.config.OnStop != nil {
 		ar.config.OnStop(autoStopped)
 	}
 }
 
+// SetThresholds updates the noise detection thresholds (dBFS),
+// usually from a per-device calibration
+func (ar *AudioSequencer) SetThresholds(noiseThreshold, triggerDecibels float64) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	ar.config.NoiseThreshold = noiseThreshold
+	ar.config.TriggerDecibels = triggerDecibels
+}
+
+// SetVoiceDetector selects the voice activity detector used by the next recording
+func (ar *AudioSequencer) SetVoiceDetector(name string) error {
+	if _, err := NewVoiceActivityDetector(name, ar.config); err != nil {
+		return err
+	}
+
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	ar.config.VoiceDetector = name
+	return nil
+}
+
+// SetChannelSelection captures captureChannels from the device and mixes the given
+// 0-based channels for the next recording. A captureChannels of 0 restores the backend downmix.
+func (ar *AudioSequencer) SetChannelSelection(captureChannels uint32, inputChannels []uint32) error {
+	if captureChannels > 0 {
+		if _, err := NewChannelMixer(captureChannels, ar.config.Channels, inputChannels); err != nil {
+			return err
+		}
+	}
+
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	ar.config.CaptureChannels = captureChannels
+	ar.config.InputChannels = inputChannels
+	if captureChannels == 0 {
+		ar.config.InputChannels = nil
+	}
+
+	return nil
+}
+
+// SetProcessing selects the pre-processing stages used by the next recording
+func (ar *AudioSequencer) SetProcessing(config ProcessingConfig) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	ar.config.Processing = config
+}
+
+// SetFramesCallback sets a callback receiving every frame from the audio thread.
+// The frame must be copied if it is kept after the callback returns.
+func (ar *AudioSequencer) SetFramesCallback(callback func([]byte)) {
+	ar.config.OnFrames = callback
+}
+
+func (ar *AudioSequencer) SetLevelCallback(callback func(AudioLevel)) {
+	ar.config.OnLevel = callback
+}
+
 func (ar *AudioSequencer) SetStopCallback(callback func(autoStopped bool)) {
 	ar.config.OnStop = callback
 }
 
-func (ar *AudioSequencer) SetSequentializeCallback(callback func([]byte)) {
+func (ar *AudioSequencer) SetSequentializeCallback(callback func(AudioChunk)) {
 	ar.config.OnSequential = callback
 }
 
@@ -251,17 +489,231 @@ func (ar *AudioSequencer) Recording() bool {
 	return ar.isRecording
 }
 
-func (ar *AudioSequencer) detectNoise(samples []byte) (bool, float64) {
+// resetSequence clears the segmentation state, but keeps the stream position.
+// Must be called with the lock held.
+func (ar *AudioSequencer) resetSequence() {
+	ar.currentBuffer = nil
+	ar.lastVoiceOffset = 0
+	ar.chunkOverlap = 0
+	ar.lastVoiceStream = ar.streamSize
+	ar.preRoll = NewRingBuffer(ar.alignToFrame(ar.durationBytes(ar.config.PreRollTime)))
+	ar.lastNoiseTime = time.Now()
+	ar.inSpeechModal = false
+
+	if ar.detector != nil {
+		ar.detector.Reset()
+	}
+}
+
+// processFrame runs the segmentation on a received frame. Must be called with the lock held.
+func (ar *AudioSequencer) processFrame(samples []byte) {
 	if len(samples) < 2 {
-		return false, 0
+		return
+	}
+
+	ar.streamSize += int64(len(samples))
+	db := ar.decibels(samples)
+
+	// Detect voice in the current frame
+	activity := ar.detector.Detect(samples, db)
+	defer func() {
+		ar.emitLevel(samples, db, len(ar.currentBuffer))
+	}()
+
+	if !ar.inSpeechModal {
+		if activity.Voice && activity.Onset {
+			ar.inSpeechModal = true
+
+			// Start the chunk with the pre-roll, so the first word is not clipped
+			ar.currentBuffer = append(ar.preRoll.Bytes(), samples...)
+			ar.lastVoiceOffset = len(ar.currentBuffer)
+			ar.preRoll.Reset()
+		} else {
+			ar.preRoll.Write(samples)
+		}
+
+		if activity.Voice {
+			ar.lastVoiceStream = ar.streamSize
+			ar.lastNoiseTime = time.Now()
+		}
+		return
+	}
+
+	ar.currentBuffer = append(ar.currentBuffer, samples...)
+
+	if activity.Voice {
+		ar.lastVoiceOffset = len(ar.currentBuffer)
+		ar.lastVoiceStream = ar.streamSize
+		ar.lastNoiseTime = time.Now()
+
+		// A speaker who never pauses would produce a single huge chunk
+		if ar.config.MaxChunkTime > 0 && ar.bytesDuration(len(ar.currentBuffer)).Milliseconds() >= ar.config.MaxChunkTime {
+			ar.split()
+		}
+		return
+	}
+
+	// Check if silence duration exceeds MaxBlankTime. The silence is measured
+	// on the stream, so the segmentation does not depend on the replay speed
+	if ar.bytesDuration(int(ar.streamSize-ar.lastVoiceStream)).Milliseconds() > ar.config.MaxBlankTime {
+		ar.flush()
+		ar.lastNoiseTime = time.Now()
 	}
+}
+
+// flush sends the current chunk, trimmed to the post-roll after the last voiced frame.
+// Must be called with the lock held.
+func (ar *AudioSequencer) flush() {
+	ar.inSpeechModal = false
+
+	if len(ar.currentBuffer) == 0 {
+		return
+	}
+
+	end := min(len(ar.currentBuffer), ar.lastVoiceOffset+ar.alignToFrame(ar.durationBytes(ar.config.PostRollTime)))
+	ar.sendChunk(end)
+
+	// The trimmed silence can still be the pre-roll of the next chunk
+	ar.preRoll.Write(ar.currentBuffer[end:])
+
+	// Clear the buffer
+	ar.currentBuffer = ar.currentBuffer[:0]
+	ar.lastVoiceOffset = 0
+	ar.chunkOverlap = 0
+}
+
+// split sends the current chunk up to the quietest frame of its tail, and keeps
+// the rest, with a small overlap, as the start of the next chunk.
+// Must be called with the lock held.
+func (ar *AudioSequencer) split() {
+	searchSize := ar.alignToFrame(ar.durationBytes(SPLIT_SEARCH_TIME))
+	overlapSize := ar.alignToFrame(ar.durationBytes(ar.config.OverlapTime))
+
+	from := max(ar.chunkOverlap+overlapSize, len(ar.currentBuffer)-searchSize)
+	end := ar.quietestOffset(from, len(ar.currentBuffer))
+	if end <= 0 {
+		return
+	}
+
+	ar.sendChunk(end)
+
+	start := max(0, end-overlapSize)
+	ar.currentBuffer = append([]byte(nil), ar.currentBuffer[start:]...)
+	ar.lastVoiceOffset = max(0, ar.lastVoiceOffset-start)
+	ar.chunkOverlap = end - start
+}
+
+// sendChunk calls OnSequential with a copy of currentBuffer[:end]. Must be called with the lock held.
+func (ar *AudioSequencer) sendChunk(end int) {
+	if ar.config.OnSequential == nil {
+		return
+	}
+
+	chunk := AudioChunk{
+		Data:     make([]byte, end),
+		Offset:   ar.bytesDuration(int(ar.streamSize) - len(ar.currentBuffer)),
+		Duration: ar.bytesDuration(end),
+		Overlap:  ar.bytesDuration(ar.chunkOverlap),
+	}
+	copy(chunk.Data, ar.currentBuffer[:end])
+
+	onSequential := ar.config.OnSequential
+	ar.pendingChunks.Add(1)
+
+	go func() {
+		defer ar.pendingChunks.Done()
+		onSequential(chunk)
+	}()
+}
+
+// quietestOffset returns the start of the quietest frame of currentBuffer[from:to]
+func (ar *AudioSequencer) quietestOffset(from, to int) int {
+	frameSize := max(2, ar.alignToFrame(ar.durationBytes(SPLIT_FRAME_TIME)))
+	from = ar.alignToFrame(max(0, from))
+
+	best := -1
+	bestDecibels := math.Inf(1)
+
+	for offset := from; offset+frameSize <= to; offset += frameSize {
+		if db := ar.decibels(ar.currentBuffer[offset : offset+frameSize]); db < bestDecibels {
+			best = offset
+			bestDecibels = db
+		}
+	}
+
+	if best < 0 {
+		return to
+	}
+	return best
+}
+
+// emitLevel calls OnLevel at most every LEVEL_INTERVAL. Must be called with the lock held.
+func (ar *AudioSequencer) emitLevel(samples []byte, rmsDecibels float64, bufferedBytes int) {
+	if ar.config.OnLevel == nil || time.Since(ar.lastLevelTime) < LEVEL_INTERVAL {
+		return
+	}
+
+	ar.lastLevelTime = time.Now()
+
+	level := AudioLevel{
+		PeakDecibels:     ar.peakDecibels(samples),
+		RMSDecibels:      rmsDecibels,
+		InSpeech:         ar.inSpeechModal,
+		BufferedDuration: ar.bytesDuration(bufferedBytes).Milliseconds(),
+	}
+
+	go ar.config.OnLevel(level)
+}
+
+// durationBytes returns the size of S16 audio data of the given duration (ms)
+func (ar *AudioSequencer) durationBytes(ms int64) int {
+	return int(ms * int64(ar.config.SampleRate) * int64(ar.config.Channels) * 2 / 1000)
+}
+
+// alignToFrame rounds the size down to a whole number of sample frames
+func (ar *AudioSequencer) alignToFrame(size int) int {
+	frameSize := int(ar.config.Channels) * 2
+	if frameSize == 0 {
+		return size
+	}
+
+	return size - size%frameSize
+}
+
+// bytesDuration returns the duration of S16 audio data of the given size
+func (ar *AudioSequencer) bytesDuration(size int) time.Duration {
+	bytesPerSecond := int64(ar.config.SampleRate) * int64(ar.config.Channels) * 2
+	if bytesPerSecond == 0 {
+		return 0
+	}
+
+	return time.Duration(int64(size) * int64(time.Second) / bytesPerSecond)
+}
+
+// peakDecibels returns the peak level of S16 samples in dBFS
+func (ar *AudioSequencer) peakDecibels(samples []byte) float64 {
+	var peak float64
+	for i := 0; i+1 < len(samples); i += 2 {
+		sample := math.Abs(float64(int16(binary.LittleEndian.Uint16(samples[i : i+2]))))
+		peak = math.Max(peak, sample)
+	}
+
+	if peak == 0 {
+		return ar.config.MinDecibels
+	}
+
+	return ar.clampDecibels(20 * math.Log10(peak/32768))
+}
 
+// decibels returns the RMS level of S16 samples in dBFS,
+// clamped between MinDecibels and MaxDecibels
+func (ar *AudioSequencer) decibels(samples []byte) float64 {
 	var sum float64
 	count := len(samples) / 2          // 2 bytes per sample for S16 format
 	maxPossibleValue := float64(32768) // Max value for 16-bit audio
 
 	// Calculate RMS (Root Mean Square) amplitude
-	for i := 0; i < len(samples); i += 2 {
+	for i := 0; i+1 < len(samples); i += 2 {
 		sample := math.Abs(float64(int16(binary.LittleEndian.Uint16(samples[i : i+2]))))
 		sum += sample * sample
 	}
@@ -271,7 +723,6 @@ func (ar *AudioSequencer) detectNoise(samples []byte) (bool, float64) {
 
 	minDecibels := ar.config.MinDecibels
 	maxDecibels := ar.config.MaxDecibels
-	noiseThreshold := ar.config.NoiseThreshold
 
 	// Calculate decibels relative to full scale (dBFS)
 	db := float64(minDecibels)
@@ -284,8 +735,7 @@ func (ar *AudioSequencer) detectNoise(samples []byte) (bool, float64) {
 		}
 	}
 
-	// Return true if the sound is above our noise threshold
-	return db > noiseThreshold, db
+	return db
 }
 
 func (ar *AudioSequencer) convertToDeviceID(interfaceData []byte) (malgo.DeviceID, error) {
@@ -303,26 +753,7 @@ func (ar *AudioSequencer) RawBytesToWAV(audioData []byte) ([]byte, error) {
 	channels := ar.config.Channels
 	sampleRate := ar.config.SampleRate
 
-	// Write WAV header
-	// RIFF chunk descriptor
-	wavBuffer.WriteString("RIFF")                             // ChunkID, 4 bytes
-	fileSize := uint32(36 + len(audioData))                   // Total file size - 8 bytes for RIFF header
-	binary.Write(&wavBuffer, binary.LittleEndian, fileSize-8) // Size (remaining bytes after this field)
-	wavBuffer.WriteString("WAVE")
-
-	// fmt sub-chunk
-	wavBuffer.WriteString("fmt ")
-	binary.Write(&wavBuffer, binary.LittleEndian, uint32(16)) // Subchunk1Size (16 for PCM)
-	binary.Write(&wavBuffer, binary.LittleEndian, uint16(1))  // AudioFormat (1 for PCM)
-	binary.Write(&wavBuffer, binary.LittleEndian, uint16(channels))
-	binary.Write(&wavBuffer, binary.LittleEndian, sampleRate)
-	binary.Write(&wavBuffer, binary.LittleEndian, uint32(sampleRate*uint32(channels)*2)) // ByteRate
-	binary.Write(&wavBuffer, binary.LittleEndian, uint16(channels*2))                    // BlockAlign
-	binary.Write(&wavBuffer, binary.LittleEndian, uint16(16))                            // BitsPerSample
-
-	// data sub-chunk
-	wavBuffer.WriteString("data")
-	binary.Write(&wavBuffer, binary.LittleEndian, uint32(len(audioData)))
+	writeWAVHeader(&wavBuffer, sampleRate, channels, uint32(len(audioData)))
 	wavBuffer.Write(audioData)
 
 	return wavBuffer.Bytes(), nil
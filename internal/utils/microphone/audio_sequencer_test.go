@@ -0,0 +1,208 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fixtureSegment is a part of a test recording: silence, or a tone standing for speech
+type fixtureSegment struct {
+	duration  time.Duration
+	amplitude float64 // 0 for silence
+}
+
+var (
+	silence = func(d time.Duration) fixtureSegment { return fixtureSegment{duration: d} }
+	speech  = func(d time.Duration) fixtureSegment { return fixtureSegment{duration: d, amplitude: 0.5} }
+)
+
+// writeFixture writes the segments as a mono 16 kHz WAV file
+func writeFixture(t *testing.T, segments ...fixtureSegment) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "fixture.wav")
+	writer, err := NewWavFileWriter(path, DEFAULT_SAMPLE_RATE, DEFAULT_CHANNELS)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var samples []byte
+	for _, segment := range segments {
+		count := int(segment.duration.Seconds() * DEFAULT_SAMPLE_RATE)
+		for i := 0; i < count; i++ {
+			value := segment.amplitude * math.Sin(2*math.Pi*440*float64(i)/DEFAULT_SAMPLE_RATE)
+			samples = binary.LittleEndian.AppendUint16(samples, uint16(int16(value*32767)))
+		}
+	}
+
+	if err := writer.Write(samples); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// replayFixture runs the file through the sequencer and returns the emitted chunks in order.
+// The recording is stopped as soon as the file has been replayed.
+func replayFixture(t *testing.T, config NoiseConfig, path string) []AudioChunk {
+	t.Helper()
+
+	source, err := NewFileSource(path, 0, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		chunks []AudioChunk
+		mu     sync.Mutex
+	)
+	stopped := make(chan struct{}, 1)
+
+	sequencer := NewCustomAudioSequencer(config)
+	sequencer.SetSequentializeCallback(func(chunk AudioChunk) {
+		mu.Lock()
+		defer mu.Unlock()
+		chunks = append(chunks, chunk)
+	})
+	sequencer.SetStopCallback(func(autoStopped bool) {
+		select {
+		case stopped <- struct{}{}:
+		default:
+		}
+	})
+
+	if err := sequencer.StartSource(source); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for !source.Ended() {
+		if time.Now().After(deadline) {
+			t.Fatal("the file was not replayed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	sequencer.Stop(false)
+
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the stop callback was not called")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Offset < chunks[j].Offset })
+	return chunks
+}
+
+func testNoiseConfig() NoiseConfig {
+	return NewAudioSequencer().GetNoiseConfig()
+}
+
+func TestSequencerChunkBoundaries(t *testing.T) {
+	config := testNoiseConfig()
+	path := writeFixture(t, silence(time.Second), speech(time.Second), silence(time.Second))
+
+	chunks := replayFixture(t, config, path)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+
+	// The chunk starts with the pre-roll and ends with the post-roll
+	chunk := chunks[0]
+	if want := time.Second - DEFAULT_PRE_ROLL_TIME*time.Millisecond; chunk.Offset != want {
+		t.Errorf("offset = %v, want %v", chunk.Offset, want)
+	}
+	if want := (DEFAULT_PRE_ROLL_TIME + 1000 + DEFAULT_POST_ROLL_TIME) * time.Millisecond; chunk.Duration != want {
+		t.Errorf("duration = %v, want %v", chunk.Duration, want)
+	}
+	if chunk.Overlap != 0 {
+		t.Errorf("overlap = %v, want 0", chunk.Overlap)
+	}
+	if want := int(chunk.Duration.Seconds() * DEFAULT_SAMPLE_RATE * 2); len(chunk.Data) != want {
+		t.Errorf("data = %d bytes, want %d", len(chunk.Data), want)
+	}
+}
+
+func TestSequencerFlushesOnStop(t *testing.T) {
+	config := testNoiseConfig()
+	// The recording is stopped while still in speech
+	path := writeFixture(t, silence(time.Second), speech(time.Second))
+
+	chunks := replayFixture(t, config, path)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+
+	chunk := chunks[0]
+	if want := time.Second - DEFAULT_PRE_ROLL_TIME*time.Millisecond; chunk.Offset != want {
+		t.Errorf("offset = %v, want %v", chunk.Offset, want)
+	}
+	if want := (DEFAULT_PRE_ROLL_TIME + 1000) * time.Millisecond; chunk.Duration != want {
+		t.Errorf("duration = %v, want %v", chunk.Duration, want)
+	}
+}
+
+func TestSequencerSplitOverlap(t *testing.T) {
+	config := testNoiseConfig()
+	config.MaxChunkTime = 1500
+	config.OverlapTime = 500
+
+	path := writeFixture(t, silence(time.Second), speech(4*time.Second), silence(time.Second))
+
+	chunks := replayFixture(t, config, path)
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want the speech split in at least 3", len(chunks))
+	}
+
+	if want := time.Second - DEFAULT_PRE_ROLL_TIME*time.Millisecond; chunks[0].Offset != want {
+		t.Errorf("first chunk offset = %v, want %v", chunks[0].Offset, want)
+	}
+	last := chunks[len(chunks)-1]
+	if end, want := last.Offset+last.Duration, (5000+DEFAULT_POST_ROLL_TIME)*time.Millisecond; end != want {
+		t.Errorf("last chunk end = %v, want %v", end, want)
+	}
+
+	overlap := time.Duration(config.OverlapTime) * time.Millisecond
+	overlapBytes := int(overlap.Seconds() * DEFAULT_SAMPLE_RATE * 2)
+
+	for i, chunk := range chunks {
+		if chunk.Duration > time.Duration(config.MaxChunkTime)*time.Millisecond {
+			t.Errorf("chunk %d lasts %v, more than the maximum", i, chunk.Duration)
+		}
+		if i == 0 {
+			if chunk.Overlap != 0 {
+				t.Errorf("chunk 0 overlap = %v, want 0", chunk.Overlap)
+			}
+			continue
+		}
+
+		previous := chunks[i-1]
+		if chunk.Overlap != overlap {
+			t.Errorf("chunk %d overlap = %v, want %v", i, chunk.Overlap, overlap)
+		}
+
+		// The overlap is the end of the previous chunk
+		if start, previousEnd := chunk.Offset+chunk.Overlap, previous.Offset+previous.Duration; start != previousEnd {
+			t.Errorf("chunk %d new audio starts at %v, the previous chunk ends at %v", i, start, previousEnd)
+		}
+		if !bytes.Equal(chunk.Data[:overlapBytes], previous.Data[len(previous.Data)-overlapBytes:]) {
+			t.Errorf("chunk %d does not start with the end of the previous chunk", i)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/gen2brain/malgo"
+)
+
+// AudioSource delivers S16 frames in the format of the sequencer NoiseConfig
+type AudioSource interface {
+	// Start begins delivering frames to onFrames, from another goroutine
+	Start(onFrames func(samples []byte)) error
+	// Stop releases the source, no frame is delivered after it returns
+	Stop()
+	// Lost reports whether the source disappeared (e.g. an unplugged device)
+	Lost() bool
+	// Ended reports whether a finite source delivered all its frames
+	Ended() bool
+}
+
+// --- Microphone source ---
+
+// MalgoSource captures a microphone input device through malgo
+type MalgoSource struct {
+	deviceID     malgo.DeviceID
+	deviceConfig malgo.DeviceConfig
+	mixer        *ChannelMixer // nil when the device delivers the sequencer format
+	ctx          *malgo.AllocatedContext
+	device       *malgo.Device
+	mu           sync.Mutex
+
+	// The backend notifies the stop of a device, e.g. when it is unplugged.
+	// Atomics, as the notification can come while Stop holds the lock.
+	stopping atomic.Bool
+	lost     atomic.Bool
+}
+
+func NewMalgoSource(deviceID malgo.DeviceID, deviceConfig malgo.DeviceConfig, mixer *ChannelMixer) *MalgoSource {
+	return &MalgoSource{
+		deviceID:     deviceID,
+		deviceConfig: deviceConfig,
+		mixer:        mixer,
+	}
+}
+
+func (s *MalgoSource) Start(onFrames func(samples []byte)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return err
+	}
+
+	deviceConfig := s.deviceConfig
+	deviceConfig.Capture.DeviceID = s.deviceID.Pointer()
+
+	mixer := s.mixer
+
+	s.stopping.Store(false)
+	s.lost.Store(false)
+
+	deviceCallbacks := malgo.DeviceCallbacks{
+		Data: func(pSample2, pSample []byte, framecount uint32) {
+			if mixer != nil {
+				pSample = mixer.Mix(pSample)
+			}
+			onFrames(pSample)
+		},
+		Stop: func() {
+			if !s.stopping.Load() {
+				s.lost.Store(true)
+			}
+		},
+	}
+
+	device, err := malgo.InitDevice(ctx.Context, deviceConfig, deviceCallbacks)
+	if err != nil {
+		ctx.Uninit()
+		ctx.Free()
+		return err
+	}
+
+	s.ctx = ctx
+	s.device = device
+
+	return device.Start()
+}
+
+func (s *MalgoSource) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopping.Store(true)
+
+	if s.device != nil {
+		s.device.Uninit()
+		s.device = nil
+	}
+
+	if s.ctx != nil {
+		s.ctx.Uninit()
+		s.ctx.Free()
+		s.ctx = nil
+	}
+}
+
+// Lost reports whether the backend stopped the device, as it does when the device is unplugged
+func (s *MalgoSource) Lost() bool {
+	return s.lost.Load()
+}
+
+func (s *MalgoSource) Ended() bool {
+	return false
+}
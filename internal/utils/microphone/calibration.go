@@ -0,0 +1,165 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Minimum gap (dB) between the noise floor and the suggested noise threshold
+	MIN_NOISE_MARGIN = 3
+
+	// Gap (dB) between the noise threshold and the trigger when no speech level is known
+	DEFAULT_TRIGGER_MARGIN = 10
+
+	MAX_CALIBRATION_TIME = 30 * time.Second
+)
+
+type CalibrationResult struct {
+	NoiseFloor    float64  // Mean room tone level (dBFS)
+	NoiseVariance float64  // Variance of the room tone level (dB²)
+	SpeechLevel   *float64 // Median speech level (dBFS), nil until the speech step is done
+
+	// Suggested values for the NoiseConfig
+	NoiseThreshold  float64
+	TriggerDecibels float64
+}
+
+// MeasureLevels captures the given device for the given duration and
+// returns the level (dBFS) of every received frame, after the pre-processing
+// so the levels match the ones seen by the voice detection.
+// It cannot be used while the sequencer is recording.
+func (ar *AudioSequencer) MeasureLevels(micInputDeviceID []byte, duration time.Duration) ([]float64, error) {
+	if ar.isRecording {
+		return nil, fmt.Errorf("cannot calibrate while recording")
+	}
+
+	if duration <= 0 || duration > MAX_CALIBRATION_TIME {
+		return nil, fmt.Errorf("calibration duration must be between 1 and %d seconds", int(MAX_CALIBRATION_TIME.Seconds()))
+	}
+
+	micDeviceID, err := ar.convertToDeviceID(micInputDeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	var levels []float64
+
+	source, err := ar.newMalgoSource(micDeviceID)
+	if err != nil {
+		return nil, err
+	}
+	processing := NewProcessingChain(ar.config.Processing, ar.config.SampleRate, ar.config.Channels)
+
+	err = source.Start(func(samples []byte) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if len(samples) >= 2 {
+			levels = append(levels, ar.decibels(processing.Process(samples)))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	time.Sleep(duration)
+	source.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(levels) == 0 {
+		return nil, fmt.Errorf("no audio received from the microphone")
+	}
+
+	return levels, nil
+}
+
+// SuggestThresholds suggests NoiseThreshold and TriggerDecibels values from
+// the room tone statistics (see NoiseStatistics). When speech levels are given,
+// the trigger is placed between the noise threshold and the speech level.
+func (ar *AudioSequencer) SuggestThresholds(noiseFloor, noiseVariance float64, speechLevels []float64) (*CalibrationResult, error) {
+	result := &CalibrationResult{
+		NoiseFloor:    noiseFloor,
+		NoiseVariance: noiseVariance,
+	}
+
+	// Frames above 2 standard deviations are rare in a steady room tone
+	margin := math.Max(MIN_NOISE_MARGIN, 2*math.Sqrt(noiseVariance))
+	result.NoiseThreshold = ar.clampDecibels(noiseFloor + margin)
+	result.TriggerDecibels = ar.clampDecibels(result.NoiseThreshold + DEFAULT_TRIGGER_MARGIN)
+
+	if len(speechLevels) == 0 {
+		return result, nil
+	}
+
+	// Only frames louder than the noise threshold are considered speech
+	var voiced []float64
+	for _, level := range speechLevels {
+		if level > result.NoiseThreshold {
+			voiced = append(voiced, level)
+		}
+	}
+
+	if len(voiced) == 0 {
+		return nil, fmt.Errorf("no speech detected above the noise floor")
+	}
+
+	speechLevel := median(voiced)
+	result.SpeechLevel = &speechLevel
+
+	// Halfway between the noise threshold and the speech level,
+	// but always a bit above the threshold
+	trigger := result.NoiseThreshold + (speechLevel-result.NoiseThreshold)/2
+	result.TriggerDecibels = ar.clampDecibels(math.Max(trigger, result.NoiseThreshold+MIN_NOISE_MARGIN))
+
+	return result, nil
+}
+
+func (ar *AudioSequencer) clampDecibels(db float64) float64 {
+	return math.Max(ar.config.MinDecibels, math.Min(ar.config.MaxDecibels, db))
+}
+
+// NoiseStatistics returns the mean and variance of room tone levels (dBFS)
+func NoiseStatistics(levels []float64) (float64, float64) {
+	return meanVariance(levels)
+}
+
+func meanVariance(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+
+	return mean, sq / float64(len(values))
+}
+
+func median(values []float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
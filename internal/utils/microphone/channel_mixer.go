@@ -0,0 +1,70 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// ChannelMixer converts the frames captured from a multi-channel interface to the
+// sequencer format, keeping only the selected input channels
+type ChannelMixer struct {
+	inputChannels  int
+	outputChannels int
+	selected       []int // 0-based input channels, averaged together
+}
+
+// NewChannelMixer creates a mixer from inputChannels to outputChannels.
+// selected contains 0-based input channels, all channels are mixed when empty.
+func NewChannelMixer(inputChannels, outputChannels uint32, selected []uint32) (*ChannelMixer, error) {
+	if inputChannels == 0 || outputChannels == 0 {
+		return nil, fmt.Errorf("invalid channel count")
+	}
+
+	mixer := &ChannelMixer{
+		inputChannels:  int(inputChannels),
+		outputChannels: int(outputChannels),
+	}
+
+	for _, channel := range selected {
+		if channel >= inputChannels {
+			return nil, fmt.Errorf("channel %d is not available, the device has %d channels", channel+1, inputChannels)
+		}
+		mixer.selected = append(mixer.selected, int(channel))
+	}
+
+	if len(mixer.selected) == 0 {
+		for channel := 0; channel < mixer.inputChannels; channel++ {
+			mixer.selected = append(mixer.selected, channel)
+		}
+	}
+
+	return mixer, nil
+}
+
+// Mix returns the selected channels mixed, duplicated on every output channel
+func (m *ChannelMixer) Mix(samples []byte) []byte {
+	inputFrameSize := m.inputChannels * 2
+	frames := len(samples) / inputFrameSize
+
+	out := make([]byte, 0, frames*m.outputChannels*2)
+
+	for i := 0; i < frames; i++ {
+		frame := samples[i*inputFrameSize : (i+1)*inputFrameSize]
+
+		var sum float64
+		for _, channel := range m.selected {
+			sum += float64(int16(binary.LittleEndian.Uint16(frame[channel*2 : channel*2+2])))
+		}
+		sample := uint16(int16(math.Max(-32768, math.Min(32767, math.Round(sum/float64(len(m.selected)))))))
+
+		for c := 0; c < m.outputChannels; c++ {
+			out = binary.LittleEndian.AppendUint16(out, sample)
+		}
+	}
+
+	return out
+}
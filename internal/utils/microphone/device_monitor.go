@@ -0,0 +1,118 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	DEVICE_CHECK_INTERVAL = 2 * time.Second
+
+	// Without frames for this long, the device is considered lost
+	DEVICE_LOST_TIMEOUT = 3 * time.Second
+)
+
+// monitorSource stops the recording when a finite source ended, and detects an
+// unplugged device, either because its backend stopped it or because it
+// stopped delivering frames
+func (ar *AudioSequencer) monitorSource() {
+	ticker := time.NewTicker(DEVICE_CHECK_INTERVAL)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if !ar.isRecording {
+			break
+		}
+
+		ar.mu.Lock()
+		source := ar.source
+		silentFor := time.Since(ar.lastFrameTime)
+		ar.mu.Unlock()
+
+		if source == nil {
+			continue
+		}
+
+		if source.Ended() {
+			slog.Debug("Audio source ended")
+			ar.Stop(true)
+			break
+		}
+
+		if silentFor < DEVICE_LOST_TIMEOUT && !source.Lost() {
+			continue
+		}
+
+		slog.Debug("Microphone input device lost", "no_frames_for", silentFor)
+
+		if ar.config.OnDeviceLost != nil {
+			ar.config.OnDeviceLost()
+		}
+
+		// Give a switched device the time to start
+		ar.mu.Lock()
+		ar.lastFrameTime = time.Now()
+		ar.mu.Unlock()
+	}
+}
+
+// SwitchDevice replaces the capture device of the current recording
+func (ar *AudioSequencer) SwitchDevice(micInputDeviceID []byte) error {
+	micDeviceID, err := ar.convertToDeviceID(micInputDeviceID)
+	if err != nil {
+		return err
+	}
+
+	source, err := ar.newMalgoSource(micDeviceID)
+	if err != nil {
+		return err
+	}
+
+	return ar.SwitchSource(source)
+}
+
+// SwitchSource replaces the audio source of the current recording.
+// The current speech chunk is flushed and the session continues on the new source.
+func (ar *AudioSequencer) SwitchSource(source AudioSource) error {
+	if !ar.isRecording {
+		return fmt.Errorf("not recording")
+	}
+
+	// The settings of the new device may select another detector and processing
+	detector, err := NewVoiceActivityDetector(ar.config.VoiceDetector, ar.config)
+	if err != nil {
+		return err
+	}
+
+	ar.mu.Lock()
+	if ar.inSpeechModal {
+		ar.flush()
+	}
+	oldSource := ar.source
+	ar.source = nil
+	ar.mu.Unlock()
+
+	// Stopping waits for the audio thread, it must not hold the lock
+	if oldSource != nil {
+		oldSource.Stop()
+	}
+
+	ar.mu.Lock()
+	// The stream position is kept, chunk offsets stay relative to the recorded audio
+	ar.detector = detector
+	ar.processing = NewProcessingChain(ar.config.Processing, ar.config.SampleRate, ar.config.Channels)
+	ar.resetSequence()
+	ar.source = source
+	ar.lastFrameTime = time.Now()
+	ar.mu.Unlock()
+
+	return source.Start(ar.receiveFrames)
+}
+
+func (ar *AudioSequencer) SetDeviceLostCallback(callback func()) {
+	ar.config.OnDeviceLost = callback
+}
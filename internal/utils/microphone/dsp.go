@@ -0,0 +1,300 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+const (
+	DEFAULT_HIGH_PASS_CUTOFF = 80 // Hz, below the fundamental of most voices
+
+	DEFAULT_AGC_TARGET = -20 // dBFS
+
+	// AGC limits, so silence and very weak signals are not boosted to noise
+	AGC_MAX_GAIN      = 10  // +20 dB
+	AGC_MIN_GAIN      = 0.1 // -20 dB
+	AGC_GATE_DECIBELS = -55 // Frames below are not considered to adapt the gain
+
+	// Spectral noise reduction
+	NOISE_REDUCTION_BLOCK      = 256 // Samples per analysis block (50% overlap)
+	NOISE_REDUCTION_LEARN      = 10  // Blocks used to learn the initial noise spectrum
+	NOISE_REDUCTION_SUBTRACT   = 2.0 // Over-subtraction factor
+	NOISE_REDUCTION_FLOOR_GAIN = 0.1 // Minimum gain of a bin, avoids musical noise
+)
+
+type ProcessingConfig struct {
+	HighPassFilter bool
+	HighPassCutoff float64 // Hz (80 default)
+	NoiseReduction bool
+	AutoGain       bool
+	AutoGainTarget float64 // dBFS (-20 default)
+}
+
+// AudioProcessor transforms a stream of float samples of a single channel
+type AudioProcessor interface {
+	Process(samples []float64)
+}
+
+// ProcessingChain runs the enabled processors on S16 frames, before the
+// voice activity detection and the chunks sent to the transcription providers
+type ProcessingChain struct {
+	channels   int
+	processors [][]AudioProcessor // Per channel
+	buffers    [][]float64
+}
+
+func NewProcessingChain(config ProcessingConfig, sampleRate, channels uint32) *ProcessingChain {
+	chain := &ProcessingChain{
+		channels:   int(max(1, channels)),
+		processors: make([][]AudioProcessor, max(1, channels)),
+		buffers:    make([][]float64, max(1, channels)),
+	}
+
+	cutoff := config.HighPassCutoff
+	if cutoff <= 0 {
+		cutoff = DEFAULT_HIGH_PASS_CUTOFF
+	}
+
+	target := config.AutoGainTarget
+	if target == 0 {
+		target = DEFAULT_AGC_TARGET
+	}
+
+	for c := range chain.processors {
+		if config.HighPassFilter {
+			chain.processors[c] = append(chain.processors[c], NewHighPassFilter(cutoff, float64(sampleRate)))
+		}
+		if config.NoiseReduction {
+			chain.processors[c] = append(chain.processors[c], NewNoiseReducer())
+		}
+		if config.AutoGain {
+			chain.processors[c] = append(chain.processors[c], NewAutoGain(target))
+		}
+	}
+
+	return chain
+}
+
+func (p *ProcessingChain) Enabled() bool {
+	return len(p.processors[0]) > 0
+}
+
+// Process returns the processed copy of the S16 interleaved samples
+func (p *ProcessingChain) Process(samples []byte) []byte {
+	if !p.Enabled() {
+		return samples
+	}
+
+	frames := len(samples) / 2 / p.channels
+
+	for c := range p.buffers {
+		if cap(p.buffers[c]) < frames {
+			p.buffers[c] = make([]float64, frames)
+		}
+		buffer := p.buffers[c][:frames]
+
+		for i := range buffer {
+			offset := (i*p.channels + c) * 2
+			buffer[i] = float64(int16(binary.LittleEndian.Uint16(samples[offset:offset+2]))) / 32768
+		}
+
+		for _, processor := range p.processors[c] {
+			processor.Process(buffer)
+		}
+	}
+
+	out := make([]byte, frames*p.channels*2)
+	for c := range p.buffers {
+		for i, value := range p.buffers[c][:frames] {
+			offset := (i*p.channels + c) * 2
+			sample := int16(math.Max(-32768, math.Min(32767, math.Round(value*32768))))
+			binary.LittleEndian.PutUint16(out[offset:offset+2], uint16(sample))
+		}
+	}
+
+	return out
+}
+
+// --- High-pass filter ---
+
+// HighPassFilter is a 2nd order Butterworth high-pass biquad, removing rumble
+type HighPassFilter struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func NewHighPassFilter(cutoff, sampleRate float64) *HighPassFilter {
+	w0 := 2 * math.Pi * cutoff / sampleRate
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / math.Sqrt2 // Q = 1/√2
+	a0 := 1 + alpha
+
+	return &HighPassFilter{
+		b0: (1 + cos) / 2 / a0,
+		b1: -(1 + cos) / a0,
+		b2: (1 + cos) / 2 / a0,
+		a1: -2 * cos / a0,
+		a2: (1 - alpha) / a0,
+	}
+}
+
+func (f *HighPassFilter) Process(samples []float64) {
+	for i, x := range samples {
+		y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+
+		f.x2, f.x1 = f.x1, x
+		f.y2, f.y1 = f.y1, y
+
+		samples[i] = y
+	}
+}
+
+// --- Spectral noise reduction ---
+
+// NoiseReducer is a spectral subtraction with overlap-add. The noise spectrum is
+// learned on the first blocks, then follows the blocks that are close to it.
+// It delays the signal by half a block.
+type NoiseReducer struct {
+	window   []float64 // sqrt Hann, used for analysis and synthesis
+	noise    []float64 // Noise power per bin
+	learned  int
+	input    []float64 // Samples waiting for a complete hop
+	previous []float64 // Second half of the last block
+	overlap  []float64 // Synthesis tail to add to the next block
+	output   []float64 // Processed samples not yet returned
+	spectrum []complex128
+}
+
+func NewNoiseReducer() *NoiseReducer {
+	size := NOISE_REDUCTION_BLOCK
+	hop := size / 2
+
+	window := make([]float64, size)
+	for i := range window {
+		window[i] = math.Sqrt(0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size)))
+	}
+
+	return &NoiseReducer{
+		window:   window,
+		noise:    make([]float64, size/2+1),
+		previous: make([]float64, hop),
+		overlap:  make([]float64, hop),
+		output:   make([]float64, hop), // Latency of one hop
+		spectrum: make([]complex128, size),
+	}
+}
+
+func (r *NoiseReducer) Process(samples []float64) {
+	hop := NOISE_REDUCTION_BLOCK / 2
+	r.input = append(r.input, samples...)
+
+	for len(r.input) >= hop {
+		r.processBlock(r.input[:hop])
+		r.input = r.input[hop:]
+	}
+
+	n := copy(samples, r.output)
+	r.output = r.output[n:]
+}
+
+func (r *NoiseReducer) processBlock(hopSamples []float64) {
+	size := NOISE_REDUCTION_BLOCK
+	hop := size / 2
+
+	for i := 0; i < hop; i++ {
+		r.spectrum[i] = complex(r.previous[i]*r.window[i], 0)
+		r.spectrum[hop+i] = complex(hopSamples[i]*r.window[hop+i], 0)
+	}
+	copy(r.previous, hopSamples)
+
+	fft(r.spectrum)
+
+	bins := size/2 + 1
+	power := make([]float64, bins)
+	var blockPower, noisePower float64
+	for k := 0; k < bins; k++ {
+		re, im := real(r.spectrum[k]), imag(r.spectrum[k])
+		power[k] = re*re + im*im
+		blockPower += power[k]
+		noisePower += r.noise[k]
+	}
+
+	switch {
+	case r.learned < NOISE_REDUCTION_LEARN:
+		// Average of the first blocks
+		r.learned++
+		for k := range r.noise {
+			r.noise[k] += (power[k] - r.noise[k]) / float64(r.learned)
+		}
+	case blockPower < 2*noisePower:
+		// Close to the noise: follow slow changes of the room
+		for k := range r.noise {
+			r.noise[k] = 0.95*r.noise[k] + 0.05*power[k]
+		}
+	}
+
+	for k := 0; k < bins; k++ {
+		gain := NOISE_REDUCTION_FLOOR_GAIN
+		if power[k] > 0 {
+			gain = math.Sqrt(math.Max(1-NOISE_REDUCTION_SUBTRACT*r.noise[k]/power[k], NOISE_REDUCTION_FLOOR_GAIN*NOISE_REDUCTION_FLOOR_GAIN))
+		}
+
+		r.spectrum[k] *= complex(gain, 0)
+		// Keep the spectrum symmetric, so the signal stays real
+		if k > 0 && k < size/2 {
+			r.spectrum[size-k] = complex(real(r.spectrum[k]), -imag(r.spectrum[k]))
+		}
+	}
+
+	ifft(r.spectrum)
+
+	for i := 0; i < hop; i++ {
+		r.output = append(r.output, r.overlap[i]+real(r.spectrum[i])*r.window[i])
+		r.overlap[i] = real(r.spectrum[hop+i]) * r.window[hop+i]
+	}
+}
+
+// --- Automatic gain control ---
+
+// AutoGain brings the loudness of the voice to a target level
+type AutoGain struct {
+	target float64 // Linear RMS
+	gain   float64
+}
+
+func NewAutoGain(targetDecibels float64) *AutoGain {
+	return &AutoGain{
+		target: math.Pow(10, targetDecibels/20),
+		gain:   1,
+	}
+}
+
+func (g *AutoGain) Process(samples []float64) {
+	if len(samples) == 0 {
+		return
+	}
+
+	var sum float64
+	for _, s := range samples {
+		sum += s * s
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+
+	if rms > 0 && 20*math.Log10(rms) > AGC_GATE_DECIBELS {
+		desired := math.Max(AGC_MIN_GAIN, math.Min(AGC_MAX_GAIN, g.target/rms))
+
+		// Fast attack on loud frames, slow release
+		if desired < g.gain {
+			g.gain += (desired - g.gain) * 0.5
+		} else {
+			g.gain += (desired - g.gain) * 0.05
+		}
+	}
+
+	for i := range samples {
+		samples[i] *= g.gain
+	}
+}
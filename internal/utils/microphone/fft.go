@@ -0,0 +1,93 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"encoding/binary"
+	"math"
+	"math/cmplx"
+)
+
+// fft computes the discrete Fourier transform in place (iterative radix-2).
+// The length of x must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+
+	// Bit reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even := x[start+k]
+				odd := w * x[start+k+size/2]
+
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
+
+// ifft computes the inverse discrete Fourier transform in place
+func ifft(x []complex128) {
+	for i := range x {
+		x[i] = cmplx.Conj(x[i])
+	}
+
+	fft(x)
+
+	n := complex(float64(len(x)), 0)
+	for i := range x {
+		x[i] = cmplx.Conj(x[i]) / n
+	}
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// powerSpectrum returns the power of the first half of the spectrum of the
+// Hann windowed S16 samples, zero padded to a power of two
+func powerSpectrum(samples []byte) []float64 {
+	count := len(samples) / 2
+	if count == 0 {
+		return nil
+	}
+
+	x := make([]complex128, nextPowerOfTwo(count))
+	for i := 0; i < count; i++ {
+		sample := float64(int16(binary.LittleEndian.Uint16(samples[i*2:i*2+2]))) / 32768
+		window := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(count))
+		x[i] = complex(sample*window, 0)
+	}
+
+	fft(x)
+
+	power := make([]float64, len(x)/2)
+	for i := range power {
+		re, im := real(x[i]), imag(x[i])
+		power[i] = re*re + im*im
+	}
+
+	return power
+}
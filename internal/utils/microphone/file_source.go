@@ -0,0 +1,195 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-audio/wav"
+)
+
+// Duration of the frames delivered by the file source
+const FILE_SOURCE_PERIOD = 10 * time.Millisecond
+
+// FileSource replays a WAV file as if it was captured by a microphone.
+// The file is converted to the sample rate and channels of the sequencer.
+type FileSource struct {
+	samples    []byte // S16 samples in the target format
+	frameSize  int    // Bytes delivered every period
+	speed      float64
+	ended      bool
+	stop       chan struct{}
+	done       chan struct{}
+	mu         sync.Mutex
+	sampleRate uint32
+	channels   uint32
+}
+
+// NewFileSource loads a WAV file. A speed of 1 replays it in real time, 2 twice as fast,
+// and 0 (or less) as fast as possible.
+func NewFileSource(path string, speed float64, config NoiseConfig) (*FileSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := wav.NewDecoder(file)
+	if !decoder.IsValidFile() {
+		return nil, fmt.Errorf("invalid WAV file: %s", path)
+	}
+
+	buffer, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	sourceChannels := int(decoder.NumChans)
+	sourceFrames := len(buffer.Data) / sourceChannels
+
+	// Normalize to [-1, 1] mono
+	mono := make([]float64, sourceFrames)
+	scale := math.Pow(2, float64(decoder.BitDepth-1))
+	for i := 0; i < sourceFrames; i++ {
+		var sum float64
+		for c := 0; c < sourceChannels; c++ {
+			value := float64(buffer.Data[i*sourceChannels+c])
+			if decoder.BitDepth == 8 {
+				value -= 128 // 8-bit WAV samples are unsigned
+			}
+			sum += value / scale
+		}
+		mono[i] = sum / float64(sourceChannels)
+	}
+
+	resampled := resampleLinear(mono, decoder.SampleRate, config.SampleRate)
+
+	channels := int(max(1, config.Channels))
+	samples := make([]byte, 0, len(resampled)*channels*2)
+	for _, value := range resampled {
+		sample := uint16(int16(math.Max(-32768, math.Min(32767, value*32768))))
+		for c := 0; c < channels; c++ {
+			samples = binary.LittleEndian.AppendUint16(samples, sample)
+		}
+	}
+
+	frameSize := int(int64(config.SampleRate)*int64(FILE_SOURCE_PERIOD)/int64(time.Second)) * channels * 2
+
+	return &FileSource{
+		samples:    samples,
+		frameSize:  max(2, frameSize),
+		speed:      speed,
+		sampleRate: config.SampleRate,
+		channels:   uint32(channels),
+	}, nil
+}
+
+func (s *FileSource) Start(onFrames func(samples []byte)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stop != nil {
+		return fmt.Errorf("file source already started")
+	}
+
+	s.ended = false
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go s.replay(onFrames, s.stop, s.done)
+
+	return nil
+}
+
+func (s *FileSource) replay(onFrames func(samples []byte), stop, done chan struct{}) {
+	defer close(done)
+
+	var interval time.Duration
+	if s.speed > 0 {
+		interval = time.Duration(float64(FILE_SOURCE_PERIOD) / s.speed)
+	}
+
+	for offset := 0; offset < len(s.samples); offset += s.frameSize {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		end := min(offset+s.frameSize, len(s.samples))
+		onFrames(s.samples[offset:end])
+
+		if interval > 0 {
+			time.Sleep(interval)
+		}
+	}
+
+	s.mu.Lock()
+	s.ended = true
+	s.mu.Unlock()
+}
+
+func (s *FileSource) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+func (s *FileSource) Lost() bool {
+	return false
+}
+
+func (s *FileSource) Ended() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ended
+}
+
+// Duration returns the duration of the replayed audio
+func (s *FileSource) Duration() time.Duration {
+	bytesPerSecond := int64(s.sampleRate) * int64(s.channels) * 2
+	if bytesPerSecond == 0 {
+		return 0
+	}
+
+	return time.Duration(int64(len(s.samples)) * int64(time.Second) / bytesPerSecond)
+}
+
+func resampleLinear(samples []float64, fromRate, toRate uint32) []float64 {
+	if fromRate == toRate || fromRate == 0 || toRate == 0 || len(samples) == 0 {
+		return samples
+	}
+
+	ratio := float64(fromRate) / float64(toRate)
+	out := make([]float64, int(float64(len(samples))/ratio))
+
+	for i := range out {
+		position := float64(i) * ratio
+		index := int(position)
+		fraction := position - float64(index)
+
+		next := index
+		if index+1 < len(samples) {
+			next = index + 1
+		}
+
+		out[i] = samples[index]*(1-fraction) + samples[next]*fraction
+	}
+
+	return out
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+// RingBuffer keeps the last written bytes up to its capacity
+type RingBuffer struct {
+	data  []byte
+	start int
+	size  int
+}
+
+func NewRingBuffer(capacity int) *RingBuffer {
+	return &RingBuffer{data: make([]byte, capacity)}
+}
+
+func (r *RingBuffer) Write(p []byte) {
+	capacity := len(r.data)
+	if capacity == 0 {
+		return
+	}
+
+	// Only the tail of p can fit
+	if len(p) >= capacity {
+		copy(r.data, p[len(p)-capacity:])
+		r.start = 0
+		r.size = capacity
+		return
+	}
+
+	for _, b := range p {
+		end := (r.start + r.size) % capacity
+		r.data[end] = b
+
+		if r.size < capacity {
+			r.size++
+		} else {
+			r.start = (r.start + 1) % capacity
+		}
+	}
+}
+
+// Bytes returns a copy of the buffered bytes, oldest first
+func (r *RingBuffer) Bytes() []byte {
+	out := make([]byte, r.size)
+
+	n := copy(out, r.data[r.start:min(r.start+r.size, len(r.data))])
+	copy(out[n:], r.data[:r.size-n])
+
+	return out
+}
+
+func (r *RingBuffer) Len() int {
+	return r.size
+}
+
+func (r *RingBuffer) Reset() {
+	r.start = 0
+	r.size = 0
+}
@@ -0,0 +1,212 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	VAD_DECIBEL  = "decibel"  // Frame level only
+	VAD_SPECTRAL = "spectral" // Spectral features with hangover smoothing
+
+	DEFAULT_VAD = VAD_DECIBEL
+)
+
+type VoiceActivity struct {
+	Voice bool // The frame belongs to speech (or to its hangover)
+	Onset bool // The frame is strong enough to start a speech segment
+}
+
+// VoiceActivityDetector classifies S16 frames as speech or not.
+// A detector is stateful and is used by a single recording at a time.
+type VoiceActivityDetector interface {
+	// Detect analyses a frame of samples whose level is db (dBFS)
+	Detect(samples []byte, db float64) VoiceActivity
+	// Reset clears the state kept between frames
+	Reset()
+}
+
+func GetVoiceDetectors() []string {
+	return []string{VAD_DECIBEL, VAD_SPECTRAL}
+}
+
+func NewVoiceActivityDetector(name string, config NoiseConfig) (VoiceActivityDetector, error) {
+	switch name {
+	case VAD_DECIBEL, "":
+		return NewDecibelDetector(config), nil
+	case VAD_SPECTRAL:
+		return NewSpectralDetector(config), nil
+	}
+
+	return nil, fmt.Errorf("unknown voice activity detector: %s", name)
+}
+
+// --- Decibel detector ---
+
+// DecibelDetector is the historical detector: any frame above the noise threshold is voice
+type DecibelDetector struct {
+	noiseThreshold  float64
+	triggerDecibels float64
+}
+
+func NewDecibelDetector(config NoiseConfig) *DecibelDetector {
+	return &DecibelDetector{
+		noiseThreshold:  config.NoiseThreshold,
+		triggerDecibels: config.TriggerDecibels,
+	}
+}
+
+func (d *DecibelDetector) Detect(samples []byte, db float64) VoiceActivity {
+	voice := db > d.noiseThreshold
+
+	return VoiceActivity{
+		Voice: voice,
+		Onset: voice && db >= d.triggerDecibels,
+	}
+}
+
+func (d *DecibelDetector) Reset() {}
+
+// --- Spectral detector ---
+
+const (
+	// Speech energy is mostly located in this band
+	SPEECH_BAND_LOW_HZ  = 250
+	SPEECH_BAND_HIGH_HZ = 4000
+
+	// Minimum ratio of the frame energy that must be in the speech band
+	MIN_SPEECH_BAND_RATIO = 0.5
+	// Noise (HVAC, hiss, clicks) has a flat spectrum, voiced speech does not
+	MAX_SPECTRAL_FLATNESS = 0.5
+
+	// Level above the adaptive noise floor to consider a frame as speech (dB)
+	SPECTRAL_FLOOR_MARGIN = 6
+
+	// Speech must last this long before a segment starts (rejects clicks)
+	SPEECH_ONSET_MS = 60
+	// Speech is kept this long after the last voiced frame (avoids fragments)
+	SPEECH_HANGOVER_MS = 300
+)
+
+type SpectralDetector struct {
+	sampleRate     uint32
+	channels       uint32
+	noiseThreshold float64
+
+	noiseFloor    float64
+	hasNoiseFloor bool
+	voicedMs      float64
+	hangoverMs    float64
+}
+
+func NewSpectralDetector(config NoiseConfig) *SpectralDetector {
+	return &SpectralDetector{
+		sampleRate:     config.SampleRate,
+		channels:       config.Channels,
+		noiseThreshold: config.NoiseThreshold,
+	}
+}
+
+func (d *SpectralDetector) Detect(samples []byte, db float64) VoiceActivity {
+	frameMs := d.frameDuration(samples)
+
+	if !d.hasNoiseFloor {
+		d.noiseFloor = db
+		d.hasNoiseFloor = true
+	}
+
+	voiced := db > d.noiseThreshold &&
+		db > d.noiseFloor+SPECTRAL_FLOOR_MARGIN &&
+		d.isSpeechSpectrum(samples)
+
+	if voiced {
+		d.voicedMs += frameMs
+	} else {
+		d.voicedMs = 0
+		d.updateNoiseFloor(db)
+	}
+
+	onset := d.voicedMs >= SPEECH_ONSET_MS
+	if onset {
+		d.hangoverMs = SPEECH_HANGOVER_MS
+	} else if d.hangoverMs > 0 {
+		d.hangoverMs = math.Max(0, d.hangoverMs-frameMs)
+	}
+
+	return VoiceActivity{
+		Voice: onset || d.hangoverMs > 0,
+		Onset: onset,
+	}
+}
+
+func (d *SpectralDetector) Reset() {
+	d.hasNoiseFloor = false
+	d.voicedMs = 0
+	d.hangoverMs = 0
+}
+
+// The noise floor follows quieter frames immediately and louder ones slowly
+func (d *SpectralDetector) updateNoiseFloor(db float64) {
+	if db < d.noiseFloor {
+		d.noiseFloor = db
+	} else {
+		d.noiseFloor = 0.98*d.noiseFloor + 0.02*db
+	}
+}
+
+func (d *SpectralDetector) isSpeechSpectrum(samples []byte) bool {
+	power := powerSpectrum(d.firstChannel(samples))
+	if len(power) == 0 {
+		return false
+	}
+
+	binHz := float64(d.sampleRate) / float64(len(power)*2)
+
+	var total, band, logSum float64
+	bandBins := 0
+
+	for i, p := range power {
+		total += p
+
+		hz := float64(i) * binHz
+		if hz >= SPEECH_BAND_LOW_HZ && hz <= SPEECH_BAND_HIGH_HZ {
+			band += p
+			logSum += math.Log(p + 1e-12)
+			bandBins++
+		}
+	}
+
+	if total == 0 || bandBins == 0 {
+		return false
+	}
+
+	// Geometric mean over arithmetic mean of the band power
+	flatness := math.Exp(logSum/float64(bandBins)) / (band/float64(bandBins) + 1e-12)
+
+	return band/total >= MIN_SPEECH_BAND_RATIO && flatness <= MAX_SPECTRAL_FLATNESS
+}
+
+func (d *SpectralDetector) firstChannel(samples []byte) []byte {
+	if d.channels <= 1 {
+		return samples
+	}
+
+	stride := int(d.channels) * 2
+	mono := make([]byte, 0, len(samples)/int(d.channels))
+	for i := 0; i+1 < len(samples); i += stride {
+		mono = append(mono, samples[i], samples[i+1])
+	}
+	return mono
+}
+
+func (d *SpectralDetector) frameDuration(samples []byte) float64 {
+	if d.sampleRate == 0 || d.channels == 0 {
+		return 0
+	}
+
+	frames := len(samples) / 2 / int(d.channels)
+	return float64(frames) * 1000 / float64(d.sampleRate)
+}
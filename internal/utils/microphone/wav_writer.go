@@ -0,0 +1,132 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package microphone
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const WAV_HEADER_SIZE = 44
+
+// WavFileWriter streams S16 samples to a WAV file.
+// The header sizes are written when the file is closed.
+type WavFileWriter struct {
+	file       *os.File
+	writer     *bufio.Writer
+	sampleRate uint32
+	channels   uint32
+	dataSize   uint32
+	mu         sync.Mutex
+}
+
+func NewWavFileWriter(path string, sampleRate, channels uint32) (*WavFileWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WavFileWriter{
+		file:       file,
+		writer:     bufio.NewWriterSize(file, 64*1024),
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+
+	// Placeholder header, completed on Close
+	if err := writeWAVHeader(w.writer, sampleRate, channels, 0); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *WavFileWriter) Write(samples []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return os.ErrClosed
+	}
+
+	n, err := w.writer.Write(samples)
+	w.dataSize += uint32(n)
+
+	return err
+}
+
+// Duration returns the duration of the written samples
+func (w *WavFileWriter) Duration() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	bytesPerSecond := int64(w.sampleRate) * int64(w.channels) * 2
+	if bytesPerSecond == 0 {
+		return 0
+	}
+
+	return time.Duration(int64(w.dataSize) * int64(time.Second) / bytesPerSecond)
+}
+
+// Size returns the size of the file, header included
+func (w *WavFileWriter) Size() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return int64(w.dataSize) + WAV_HEADER_SIZE
+}
+
+func (w *WavFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		return nil
+	}
+
+	file := w.file
+	w.file = nil
+	defer file.Close()
+
+	if err := w.writer.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return writeWAVHeader(file, w.sampleRate, w.channels, w.dataSize)
+}
+
+func writeWAVHeader(w io.Writer, sampleRate, channels, dataSize uint32) error {
+	header := make([]byte, 0, WAV_HEADER_SIZE)
+
+	// RIFF chunk descriptor
+	header = append(header, "RIFF"...)
+	header = binary.LittleEndian.AppendUint32(header, 36+dataSize) // Size (remaining bytes after this field)
+	header = append(header, "WAVE"...)
+
+	// fmt sub-chunk
+	header = append(header, "fmt "...)
+	header = binary.LittleEndian.AppendUint32(header, 16) // Subchunk1Size (16 for PCM)
+	header = binary.LittleEndian.AppendUint16(header, 1)  // AudioFormat (1 for PCM)
+	header = binary.LittleEndian.AppendUint16(header, uint16(channels))
+	header = binary.LittleEndian.AppendUint32(header, sampleRate)
+	header = binary.LittleEndian.AppendUint32(header, sampleRate*channels*2) // ByteRate
+	header = binary.LittleEndian.AppendUint16(header, uint16(channels*2))    // BlockAlign
+	header = binary.LittleEndian.AppendUint16(header, 16)                    // BitsPerSample
+
+	// data sub-chunk
+	header = append(header, "data"...)
+	header = binary.LittleEndian.AppendUint32(header, dataSize)
+
+	_, err := w.Write(header)
+	return err
+}
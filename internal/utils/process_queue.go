@@ -15,6 +15,9 @@ type ProcessQueue struct {
 	lock           sync.Mutex
 	executionId    BookID
 	name           string
+
+	// Booked processes not executed yet
+	pending sync.WaitGroup
 }
 
 type process struct {
@@ -36,6 +39,7 @@ func NewProcessQueue(name string) *ProcessQueue {
 
 func (pq *ProcessQueue) Book() BookID {
 	pq.processCounter++
+	pq.pending.Add(1)
 	newId := BookID(pq.processCounter)
 
 	pq.processes[newId] = &process{
@@ -64,6 +68,11 @@ func (pq *ProcessQueue) Add(id BookID, callback func()) {
 	}()
 }
 
+// Wait blocks until every booked process has been executed
+func (pq *ProcessQueue) Wait() {
+	pq.pending.Wait()
+}
+
 func (pq *ProcessQueue) worker() {
 	pq.lock.Lock()
 	defer pq.lock.Unlock()
@@ -91,6 +100,7 @@ func (pq *ProcessQueue) worker() {
 	}
 
 	delete(pq.processes, pq.executionId)
+	pq.pending.Done()
 
 	// Recursively call the worker
 	go pq.worker()
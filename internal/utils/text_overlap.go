@@ -0,0 +1,45 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package utils
+
+import (
+	"myscript/internal/normalize"
+	"strings"
+	"unicode"
+)
+
+// TrimOverlap removes from the start of next the words that repeat the end of previous.
+// It is used to join transcripts of audio chunks that share a few hundred milliseconds.
+// At most maxWords words are compared.
+func TrimOverlap(previous, next string, maxWords int) string {
+	previousWords := strings.Fields(previous)
+	nextWords := strings.Fields(next)
+
+	limit := min(maxWords, len(previousWords), len(nextWords))
+
+	// Longest run of words ending previous and starting next
+	for size := limit; size > 0; size-- {
+		if wordsEqual(previousWords[len(previousWords)-size:], nextWords[:size]) {
+			return strings.Join(nextWords[size:], " ")
+		}
+	}
+
+	return next
+}
+
+func wordsEqual(a, b []string) bool {
+	for i := range a {
+		if comparableWord(a[i]) != comparableWord(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// comparableWord ignores the case, diacritics and surrounding punctuation of a word
+func comparableWord(word string) string {
+	return normalize.Fold(strings.TrimFunc(normalize.UnifyPunctuation(word), func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSymbol(r)
+	}))
+}
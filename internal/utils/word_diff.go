@@ -0,0 +1,163 @@
+// Copyright (c) 2024
+// Licensed under the MIT License. See LICENSE file in the root directory.
+
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	DIFF_EQUAL  = "equal"
+	DIFF_INSERT = "insert"
+	DIFF_DELETE = "delete"
+
+	// Above this number of edits, the texts are reported as fully replaced
+	MAX_DIFF_EDITS = 2000
+)
+
+// A word with the whitespace that follows it
+var diffTokenRegex = regexp.MustCompile(`\S+\s*`)
+
+type DiffPart struct {
+	Type string `json:"type"` // equal, insert or delete
+	Text string `json:"text"`
+}
+
+// DiffWords returns the word-level changes that turn a into b.
+// A change of whitespace alone is not reported, equal parts keep the whitespace of b.
+func DiffWords(a, b string) []DiffPart {
+	aTokens := diffTokenRegex.FindAllString(strings.TrimSpace(a), -1)
+	bTokens := diffTokenRegex.FindAllString(strings.TrimSpace(b), -1)
+
+	parts := []DiffPart{}
+	add := func(partType, text string) {
+		if last := len(parts) - 1; last >= 0 && parts[last].Type == partType {
+			parts[last].Text += text
+			return
+		}
+		parts = append(parts, DiffPart{Type: partType, Text: text})
+	}
+
+	// Common prefix and suffix are left out of the search
+	prefix := 0
+	for prefix < len(aTokens) && prefix < len(bTokens) && sameWord(aTokens[prefix], bTokens[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(aTokens)-prefix && suffix < len(bTokens)-prefix &&
+		sameWord(aTokens[len(aTokens)-1-suffix], bTokens[len(bTokens)-1-suffix]) {
+		suffix++
+	}
+
+	if prefix > 0 {
+		add(DIFF_EQUAL, strings.Join(bTokens[:prefix], ""))
+	}
+
+	for _, part := range diffTokens(aTokens[prefix:len(aTokens)-suffix], bTokens[prefix:len(bTokens)-suffix]) {
+		add(part.Type, part.Text)
+	}
+
+	if suffix > 0 {
+		add(DIFF_EQUAL, strings.Join(bTokens[len(bTokens)-suffix:], ""))
+	}
+
+	return parts
+}
+
+func sameWord(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+// diffTokens finds the shortest edit script with the Myers algorithm
+func diffTokens(a, b []string) []DiffPart {
+	n, m := len(a), len(b)
+	maxEdits := min(n+m, MAX_DIFF_EDITS)
+
+	offset := maxEdits + 1
+	v := make([]int, 2*offset+1)
+
+	// Furthest x reached on each diagonal k in [-d, d], before each step d
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= maxEdits && !found; d++ {
+		trace = append(trace, slices.Clone(v[offset-d:offset+d+1]))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Insertion
+			} else {
+				x = v[offset+k-1] + 1 // Deletion
+			}
+
+			y := x - k
+			for x < n && y < m && sameWord(a[x], b[y]) {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		parts := []DiffPart{}
+		if n > 0 {
+			parts = append(parts, DiffPart{Type: DIFF_DELETE, Text: strings.Join(a, "")})
+		}
+		if m > 0 {
+			parts = append(parts, DiffPart{Type: DIFF_INSERT, Text: strings.Join(b, "")})
+		}
+		return parts
+	}
+
+	// Walk the trace back from the end, the parts are collected in reverse
+	var reversed []DiffPart
+	x, y := n, m
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		step := trace[d]
+		k := x - y
+
+		var previousK int
+		if k == -d || (k != d && step[k-1+d] < step[k+1+d]) {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+
+		previousX := 0
+		if d > 0 {
+			previousX = step[previousK+d]
+		}
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			reversed = append(reversed, DiffPart{Type: DIFF_EQUAL, Text: b[y-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == previousX {
+				reversed = append(reversed, DiffPart{Type: DIFF_INSERT, Text: b[y-1]})
+			} else {
+				reversed = append(reversed, DiffPart{Type: DIFF_DELETE, Text: a[x-1]})
+			}
+		}
+
+		x, y = previousX, previousY
+	}
+
+	slices.Reverse(reversed)
+
+	return reversed
+}
@@ -102,7 +102,8 @@ func main() {
 		MinHeight: height,
 		Logger:    logger.Logger,
 		AssetServer: &assetserver.Options{
-			Assets: assets,
+			Assets:  assets,
+			Handler: app.recordingsHandler(),
 		},
 		WindowStartState: options.Maximised,
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},